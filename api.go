@@ -0,0 +1,263 @@
+package main
+
+import ( // Import required packages:
+	"encoding/json" // For JSON encoding and decoding
+	"errors"        // For creating error values
+	"log"           // For logging errors
+	"math/rand"     // For the predictions' random source
+	"net/http"      // For HTTP request handling
+	"strconv"       // For converting strings to integers
+)
+
+var ( // Errors returned for the season parameter
+	errInvalidSeason  = errors.New("invalid season parameter")
+	errSeasonNotFound = errors.New("season not found") // Returned for a season the league does not have
+)
+
+func (srv *server) tableAPIHandler(w http.ResponseWriter, r *http.Request) { // tableAPIHandler sends the league table as JSON
+	_, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	teams, err := leagueTable(store) // Same data and order as the HTML league table
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch league table") // Return error if query fails
+		return
+	}
+
+	writeJSON(w, teams)
+}
+
+func (srv *server) matchesAPIHandler(w http.ResponseWriter, r *http.Request) { // matchesAPIHandler sends the matches of a week, or of the whole season, as JSON (current season unless season is given)
+	_, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	week := 0 // Default to every week when no week is given
+	if weekStr := r.URL.Query().Get("week"); weekStr != "" {
+		var err error
+		week, err = strconv.Atoi(weekStr) // Convert week from string to int
+		if err != nil || week < 1 {
+			writeError(w, r, http.StatusBadRequest, "invalid_week", "Invalid week parameter") // Return error for invalid week
+			return
+		}
+	}
+
+	seasonID, err := requestSeasonID(store, r) // Season to list, defaulting to the current one
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch season") // 400 for an invalid season, 404 for a season the league does not have or before its first season
+		return
+	}
+
+	matches, err := store.Matches(seasonID, week) // Same data as the HTML match results, including unplayed fixtures
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch matches") // Return error if query fails
+		return
+	}
+
+	writeJSON(w, matches)
+}
+
+func (srv *server) editMatchHandler(w http.ResponseWriter, r *http.Request) { // editMatchHandler corrects the score of a played match and returns the recomputed table and predictions
+	if r.Method != http.MethodPost {
+		writeError(w, r, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed") // Only POST changes a result
+		return
+	}
+
+	var edit struct { // JSON body with the match ID and its corrected score
+		ID        int `json:"id"`
+		HomeScore int `json:"homeScore"`
+		AwayScore int `json:"awayScore"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&edit); err != nil || edit.HomeScore < 0 || edit.AwayScore < 0 {
+		writeError(w, r, http.StatusBadRequest, "invalid_input", "Invalid input") // Return error for invalid body or negative scores
+		return
+	}
+
+	league, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	unlock, ok := srv.lockLeague(w, r, league.ID) // Only one request may change the league at a time
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	season, err := store.Season() // Only the current season's results can be edited
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch season") // Return error if query fails
+		return
+	}
+
+	match, err := editMatchResult(store, season.ID, edit.ID, edit.HomeScore, edit.AwayScore)
+	if err != nil {
+		storeError(w, r, err, "Failed to edit match") // 404 for unknown match IDs, 409 if the fixture has no result yet or the season is archived
+		return
+	}
+
+	teams, err := leagueTable(store) // Recomputed league table
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch league table") // Return error if query fails
+		return
+	}
+
+	predictions, err := predictStandings(store, season.ID, rand.New(rand.NewSource(newSeed()))) // Predictions refreshed with the corrected result
+	if err != nil {
+		storeError(w, r, err, "Failed to predict standings") // Return error if query fails
+		return
+	}
+	sortPredictions(predictions)
+
+	writeJSON(w, map[string]any{"match": match, "table": teams, "predictions": predictions})
+}
+
+func (srv *server) predictionsAPIHandler(w http.ResponseWriter, r *http.Request) { // predictionsAPIHandler sends title and finishing position probabilities as JSON
+	_, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	seed, err := parseSeed(r) // Optional seed so predictions can be reproduced
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_seed", "Invalid seed parameter") // Return error for invalid seed
+		return
+	}
+	w.Header().Set("X-Simulation-Seed", strconv.FormatInt(seed, 10)) // Report the seed used
+
+	season, err := store.Season() // Predictions are for the current season
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch season") // Return error if query fails
+		return
+	}
+
+	predictions, err := predictStandings(store, season.ID, rand.New(rand.NewSource(seed))) // Same data as the HTML predictions
+	if err != nil {
+		storeError(w, r, err, "Failed to predict standings") // Return error if query fails
+		return
+	}
+	sortPredictions(predictions)
+
+	writeJSON(w, predictions)
+}
+
+func (srv *server) seasonAPIHandler(w http.ResponseWriter, r *http.Request) { // seasonAPIHandler sends the current season state as JSON
+	_, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	season, err := store.Season()
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch season") // Return error if query fails
+		return
+	}
+
+	writeJSON(w, season)
+}
+
+func (srv *server) seasonsAPIHandler(w http.ResponseWriter, r *http.Request) { // seasonsAPIHandler sends every season, newest first, as JSON
+	_, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	seasons, err := store.Seasons()
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch seasons") // Return error if query fails
+		return
+	}
+
+	writeJSON(w, seasons)
+}
+
+func (srv *server) standingsAPIHandler(w http.ResponseWriter, r *http.Request) { // standingsAPIHandler sends the archived final standings of a season as JSON
+	_, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	seasonID, err := strconv.Atoi(r.URL.Query().Get("season")) // Convert season from string to int
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_season", "Invalid season parameter") // Return error for missing or invalid season
+		return
+	}
+
+	teams, err := archivedTable(store, seasonID) // Final standings in table order
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch standings") // 404 for a season the league does not have
+		return
+	}
+
+	writeJSON(w, teams)
+}
+
+func (srv *server) newSeasonHandler(w http.ResponseWriter, r *http.Request) { // newSeasonHandler archives the current season and starts a new one
+	if r.Method != http.MethodPost {
+		writeError(w, r, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed") // Only POST starts a season
+		return
+	}
+
+	seed, err := parseSeasonSeed(r) // Seed for the new fixtures, overridable with the seed parameter
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_seed", "Invalid seed parameter") // Return error for invalid seed
+		return
+	}
+
+	league, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	unlock, ok := srv.lockLeague(w, r, league.ID) // Only one request may change the league at a time
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	season, err := StartSeason(store, seed)
+	if err != nil {
+		storeError(w, r, err, "Failed to start season") // Return error if the season cannot be started
+		return
+	}
+
+	w.Header().Set("X-Simulation-Seed", strconv.FormatInt(seed, 10)) // Report the seed used
+	writeJSON(w, season)
+}
+
+func requestSeasonID(store LeagueStore, r *http.Request) (int, error) { // requestSeasonID returns the season query parameter, or the current season's ID when it is missing; errInvalidSeason, errSeasonNotFound or errNoSeason when there is no such season
+	seasonStr := r.URL.Query().Get("season")
+	if seasonStr == "" {
+		season, err := store.Season()
+		return season.ID, err
+	}
+	seasonID, err := strconv.Atoi(seasonStr)
+	if err != nil {
+		return 0, errInvalidSeason
+	}
+	season, err := findSeason(store, seasonID)
+	return season.ID, err
+}
+
+func findSeason(store LeagueStore, seasonID int) (Season, error) { // findSeason returns one of the league's seasons, or errSeasonNotFound
+	seasons, err := store.Seasons()
+	if err != nil {
+		return Season{}, err
+	}
+	for _, season := range seasons {
+		if season.ID == seasonID {
+			return season, nil
+		}
+	}
+	return Season{}, errSeasonNotFound
+}
+
+func writeJSON(w http.ResponseWriter, v any) { // writeJSON encodes v as the JSON response body
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println(err) // The status has already been sent, so the error can only be logged
+	}
+}
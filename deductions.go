@@ -0,0 +1,171 @@
+package main
+
+import ( // Import required packages:
+	"encoding/json" // For JSON decoding
+	"errors"        // For creating error values
+	"net/http"      // For HTTP request handling
+	"strconv"       // For converting strings to integers
+	"strings"       // For trimming reasons
+)
+
+var ( // Errors returned when a point deduction cannot be changed
+	errDeductionNotFound = errors.New("deduction not found")                                  // Returned for an unknown deduction ID
+	errSeasonArchived    = errors.New("season is finished; its final standings are archived") // Returned when deducting points or editing results after the season's last week
+)
+
+const (
+	maxDeductionPoints = 100 // Most points one deduction may take
+	maxReasonLen       = 100 // Longest deduction reason
+)
+
+type Deduction struct { // Deduction is a number of points taken from a team in the current season, e.g. for a financial breach
+	ID     int    `json:"id"`     // Deduction ID
+	TeamID int    `json:"teamId"` // Team the points are taken from
+	Team   string `json:"team"`   // Team name
+	Points int    `json:"points"` // Points taken
+	Reason string `json:"reason"` // Why the points were taken
+}
+
+func (srv *server) deductionsAPIHandler(w http.ResponseWriter, r *http.Request) { // deductionsAPIHandler lists (GET), adds (POST) and cancels (DELETE ?id=N) the point deductions of the current season
+	league, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		season, err := store.Season()
+		if err != nil {
+			storeError(w, r, err, "Failed to fetch season") // Return error if query fails
+			return
+		}
+		deductions, err := store.Deductions(season.ID)
+		if err != nil {
+			storeError(w, r, err, "Failed to fetch deductions") // Return error if query fails
+			return
+		}
+		writeJSON(w, deductions)
+		return
+	}
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		writeError(w, r, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed") // Only GET, POST and DELETE are supported
+		return
+	}
+
+	var deduction Deduction
+	deductionID := 0
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&deduction); err != nil || deduction.Points < 1 || deduction.Points > maxDeductionPoints {
+			writeError(w, r, http.StatusBadRequest, "invalid_input", "Invalid input, points must be between 1 and "+strconv.Itoa(maxDeductionPoints)) // Return error for invalid body or points
+			return
+		}
+		deduction.Reason = strings.TrimSpace(deduction.Reason)
+		if len(deduction.Reason) > maxReasonLen {
+			writeError(w, r, http.StatusBadRequest, "invalid_input", "Reason is too long") // Return error for overlong reasons
+			return
+		}
+	} else {
+		var err error
+		deductionID, err = strconv.Atoi(r.URL.Query().Get("id")) // Convert id from string to int
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "invalid_input", "Invalid id parameter") // Return error for missing or invalid id
+			return
+		}
+	}
+
+	unlock, ok := srv.lockLeague(w, r, league.ID) // Only one request may change the league at a time
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	if r.Method == http.MethodPost {
+		deduction, err := addDeduction(store, deduction)
+		if err != nil {
+			storeError(w, r, err, "Failed to add deduction") // 404 for unknown teams, 409 once the season is archived
+			return
+		}
+		w.Header().Set("Content-Type", "application/json") // Set before the status, which sends the headers
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, deduction)
+		return
+	}
+
+	if err := removeDeduction(store, deductionID); err != nil {
+		storeError(w, r, err, "Failed to remove deduction") // 404 for unknown deductions, 409 once the season is archived
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func addDeduction(store LeagueStore, deduction Deduction) (Deduction, error) { // addDeduction takes points from a team of the current season and records why
+	err := store.Update(func(tx LeagueStore) error { // Record and apply in one transaction
+		season, err := openSeason(tx)
+		if err != nil {
+			return err
+		}
+		team, err := tx.Team(deduction.TeamID)
+		if err != nil {
+			return err // errTeamNotFound for unknown or removed teams
+		}
+		deduction.Team = team.Name
+
+		if deduction, err = tx.AddDeduction(season.ID, deduction); err != nil {
+			return err
+		}
+		return adjustPoints(tx, deduction.TeamID, -deduction.Points)
+	})
+	return deduction, err
+}
+
+func removeDeduction(store LeagueStore, deductionID int) error { // removeDeduction cancels a deduction of the current season, giving the points back
+	return store.Update(func(tx LeagueStore) error { // Remove and restore in one transaction
+		season, err := openSeason(tx)
+		if err != nil {
+			return err
+		}
+		deductions, err := tx.Deductions(season.ID)
+		if err != nil {
+			return err
+		}
+		for _, deduction := range deductions {
+			if deduction.ID == deductionID {
+				if err := tx.RemoveDeduction(deductionID); err != nil {
+					return err
+				}
+				return adjustPoints(tx, deduction.TeamID, deduction.Points)
+			}
+		}
+		return errDeductionNotFound // Unknown, or made in an earlier season
+	})
+}
+
+func seasonDeductions(store LeagueStore) ([]Deduction, error) { // seasonDeductions returns the point deductions of the current season, none before the first season
+	season, err := store.Season()
+	if errors.Is(err, errNoSeason) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return store.Deductions(season.ID)
+}
+
+func openSeason(store LeagueStore) (Season, error) { // openSeason returns the current season, or errSeasonArchived once its final standings are archived
+	season, err := store.Season()
+	if err != nil {
+		return Season{}, err
+	}
+	if season.Archived {
+		return Season{}, errSeasonArchived
+	}
+	return season, nil
+}
+
+func adjustPoints(store LeagueStore, teamID, points int) error { // adjustPoints adds points (negative to deduct) to a team's total
+	team, err := store.Team(teamID)
+	if err != nil {
+		return err
+	}
+	team.Points += points
+	return store.UpdateTeam(team)
+}
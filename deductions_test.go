@@ -0,0 +1,65 @@
+package main
+
+import ( // Import required packages:
+	"errors"  // For matching error values
+	"testing" // For the test framework
+)
+
+func TestRecomputeTableWithDeductions(t *testing.T) { // Recomputing the table under new points rules keeps the season's deductions, and cancelling one gives the points back
+	store := newTestStore(t, "Chelsea", "Arsenal")
+	season := currentSeason(t, store)
+	matches, err := store.Matches(season.ID, 1)
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("week 1 fixtures = %v, %v", matches, err)
+	}
+	match := matches[0]
+	match.HomeScore, match.AwayScore, match.Played = 4, 3, true
+	if err := saveMatch(store, match); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateLeagueTable(store, match); err != nil {
+		t.Fatal(err)
+	}
+
+	deduction, err := addDeduction(store, Deduction{TeamID: match.HomeTeamID, Points: 2, Reason: "Financial breach"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := func() (int, int) { // Home and away team points
+		t.Helper()
+		home, err := store.Team(match.HomeTeamID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		away, err := store.Team(match.AwayTeamID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return home.Points, away.Points
+	}
+	if home, away := points(); home != 1 || away != 0 {
+		t.Errorf("after the deduction: %d-%d points, want 1-0", home, away)
+	}
+
+	rules := defaultRules()
+	rules.Points = bonusPoints
+	if err := store.SetRules(rules); err != nil {
+		t.Fatal(err)
+	}
+	if err := recomputeTable(store); err != nil {
+		t.Fatal(err)
+	}
+	if home, away := points(); home != 5-2 || away != 1 { // Win with goals bonus less the deduction; narrow loss bonus
+		t.Errorf("recomputed: %d-%d points, want 3-1", home, away)
+	}
+
+	if err := removeDeduction(store, deduction.ID); err != nil {
+		t.Fatal(err)
+	}
+	if home, _ := points(); home != 5 {
+		t.Errorf("after cancelling the deduction: %d points, want 5", home)
+	}
+	if err := removeDeduction(store, deduction.ID); !errors.Is(err, errDeductionNotFound) {
+		t.Errorf("cancelling twice: error = %v, want %v", err, errDeductionNotFound)
+	}
+}
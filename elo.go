@@ -0,0 +1,150 @@
+package main
+
+import ( // Import required packages:
+	"math"     // For the expected score
+	"net/http" // For HTTP request handling
+)
+
+const (
+	eloBase        = 1200 // Rating of a team of strength 0, so strengths 1-4 start at 1300-1600
+	eloPerStrength = 100  // Rating points per point of strength
+	eloK           = 20   // Most rating points a team can win or lose in one match
+	eloScale       = 400  // Rating difference at which the stronger team is expected to score 10 times as often
+	minEloStrength = 0.25 // Lowest strength a rating is turned into, so the match engine always has a positive strength
+)
+
+type Rating struct { // Rating is a team's Elo rating after a week of a season (week 0 is the start of the season)
+	TeamID int     `json:"teamId"` // Team ID
+	Team   string  `json:"team"`   // Team name
+	Week   int     `json:"week"`   // Week the rating was reached after
+	Rating float64 `json:"rating"` // Elo rating
+}
+
+func eloForStrength(strength int) float64 { // eloForStrength returns the starting rating of a team of the given strength
+	return float64(eloBase + eloPerStrength*strength)
+}
+
+func eloStrength(rating float64) float64 { // eloStrength turns a rating back into a match engine strength, so a team that has not played keeps its strength
+	return max((rating-eloBase)/eloPerStrength, minEloStrength)
+}
+
+func eloChange(rating, opponentRating float64, goalsFor, goalsAgainst int) float64 { // eloChange returns the rating points a team wins (or loses, when negative) with a result
+	expected := 1 / (1 + math.Pow(10, (opponentRating-rating)/eloScale)) // Expected score from the rating difference
+	actual := 0.5
+	if goalsFor > goalsAgainst {
+		actual = 1
+	} else if goalsFor < goalsAgainst {
+		actual = 0
+	}
+	return eloK * (actual - expected)
+}
+
+func updateRatings(store LeagueStore, match Match) error { // updateRatings moves both teams' ratings after a match result
+	home, err := store.Team(match.HomeTeamID)
+	if err != nil {
+		return err
+	}
+	away, err := store.Team(match.AwayTeamID)
+	if err != nil {
+		return err
+	}
+
+	change := eloChange(home.Elo, away.Elo, match.HomeScore, match.AwayScore) // Points won by one side are lost by the other
+	home.Elo += change
+	away.Elo -= change
+	if err := store.UpdateTeam(home); err != nil {
+		return err
+	}
+	return store.UpdateTeam(away)
+}
+
+func recordRatings(store LeagueStore, seasonID, week int) error { // recordRatings stores every team's current rating as its rating after a week
+	teams, err := store.Teams()
+	if err != nil {
+		return err
+	}
+	ratings := make([]Rating, len(teams))
+	for i, team := range teams {
+		ratings[i] = Rating{TeamID: team.ID, Week: week, Rating: team.Elo}
+	}
+	return store.SaveRatings(seasonID, week, ratings)
+}
+
+func replayRatings(store LeagueStore, seasonID int) error { // replayRatings recomputes a season's ratings from its start, after results were changed or imported
+	history, err := store.Ratings(seasonID)
+	if err != nil {
+		return err
+	}
+	teams, err := store.Teams()
+	if err != nil {
+		return err
+	}
+
+	ratings := make(map[int]float64, len(teams)) // Rating of each team at the start of the season
+	for _, team := range teams {
+		ratings[team.ID] = team.Elo // Seasons from before ratings were recorded start from the current ratings
+	}
+	for _, rating := range history {
+		if rating.Week == 0 {
+			ratings[rating.TeamID] = rating.Rating
+		}
+	}
+
+	matches, err := store.Matches(seasonID, 0) // In week order, as the ratings are replayed
+	if err != nil {
+		return err
+	}
+
+	weekRatings := func(week int) []Rating { // Snapshot of the ratings after a week
+		snapshot := make([]Rating, 0, len(teams))
+		for _, team := range teams {
+			snapshot = append(snapshot, Rating{TeamID: team.ID, Week: week, Rating: ratings[team.ID]})
+		}
+		return snapshot
+	}
+
+	if err := store.SaveRatings(seasonID, 0, weekRatings(0)); err != nil {
+		return err
+	}
+	for i, match := range matches {
+		if match.Played {
+			change := eloChange(ratings[match.HomeTeamID], ratings[match.AwayTeamID], match.HomeScore, match.AwayScore)
+			ratings[match.HomeTeamID] += change
+			ratings[match.AwayTeamID] -= change
+		}
+		if match.Played && (i == len(matches)-1 || matches[i+1].Week != match.Week) { // Last match of a played week
+			if err := store.SaveRatings(seasonID, match.Week, weekRatings(match.Week)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, team := range teams { // The teams keep the ratings reached after the last played week
+		team.Elo = ratings[team.ID]
+		if err := store.UpdateTeam(team); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (srv *server) ratingsAPIHandler(w http.ResponseWriter, r *http.Request) { // ratingsAPIHandler sends every team's rating after each week of a season as JSON (current season unless season is given)
+	_, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	seasonID, err := requestSeasonID(store, r) // Season to list, defaulting to the current one
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch season") // 400 for an invalid season, 404 for a season the league does not have or before its first season
+		return
+	}
+
+	ratings, err := store.Ratings(seasonID)
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch ratings") // Return error if query fails
+		return
+	}
+
+	writeJSON(w, ratings)
+}
@@ -0,0 +1,114 @@
+package main
+
+import ( // Import required packages:
+	"math"    // For comparing ratings
+	"testing" // For the test framework
+)
+
+func TestEloChange(t *testing.T) { // The winner takes rating points from the loser, fewer the more it was expected to win
+	tests := []struct {
+		name                   string
+		rating, opponentRating float64
+		goalsFor, goalsAgainst int
+		want                   float64
+	}{
+		{"equal teams, win", 1500, 1500, 2, 0, eloK / 2},
+		{"equal teams, draw", 1500, 1500, 1, 1, 0},
+		{"equal teams, loss", 1500, 1500, 0, 3, -eloK / 2},
+		{"favourite wins", 1500 + eloScale, 1500, 1, 0, eloK * (1 - 10.0/11)},
+		{"underdog wins", 1500, 1500 + eloScale, 1, 0, eloK * (1 - 1.0/11)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := eloChange(tt.rating, tt.opponentRating, tt.goalsFor, tt.goalsAgainst)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("eloChange() = %v, want %v", got, tt.want)
+			}
+			if other := eloChange(tt.opponentRating, tt.rating, tt.goalsAgainst, tt.goalsFor); math.Abs(got+other) > 1e-9 {
+				t.Errorf("the opponent changes by %v, want %v", other, -got)
+			}
+		})
+	}
+}
+
+func TestEloStrength(t *testing.T) { // A team that has not played keeps its strength, and no rating gives the engine a strength below the minimum
+	for strength := minStrength; strength <= maxStrength; strength++ {
+		if got := eloStrength(eloForStrength(strength)); got != float64(strength) {
+			t.Errorf("eloStrength(eloForStrength(%d)) = %v", strength, got)
+		}
+	}
+	if got := eloStrength(eloBase - 500); got != minEloStrength {
+		t.Errorf("eloStrength(%d) = %v, want %v", eloBase-500, got, minEloStrength)
+	}
+}
+
+func TestReplayRatings(t *testing.T) { // Replaying a season's ratings from its start gives the recorded history, and an edited result is rated as if it had been played that way
+	store := newTestStore(t, "Chelsea", "Arsenal", "Manchester City", "Liverpool")
+	season := currentSeason(t, store)
+	for week := 1; week <= 2; week++ {
+		if _, err := simulateWeek(store, season, week, weekRand(1, week)); err != nil {
+			t.Fatal(err)
+		}
+		season.CurrentWeek = week
+	}
+
+	ratings := func() map[[2]int]float64 { // Recorded rating of each team after each week
+		t.Helper()
+		history, err := store.Ratings(season.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byWeek := make(map[[2]int]float64)
+		for _, rating := range history {
+			byWeek[[2]int{rating.Week, rating.TeamID}] = rating.Rating
+		}
+		return byWeek
+	}
+	played := ratings()
+
+	if err := replayRatings(store, season.ID); err != nil {
+		t.Fatal(err)
+	}
+	replayed := ratings()
+	if len(replayed) != len(played) {
+		t.Fatalf("replay recorded %d ratings, want %d", len(replayed), len(played))
+	}
+	for key, rating := range played {
+		if math.Abs(replayed[key]-rating) > 1e-9 {
+			t.Errorf("team %d after week %d: replayed %v, want %v", key[1], key[0], replayed[key], rating)
+		}
+	}
+
+	matches, err := store.Matches(season.ID, 1)
+	if err != nil || len(matches) == 0 {
+		t.Fatalf("week 1 matches = %v, %v", matches, err)
+	}
+	match := matches[0]
+	edited, err := editMatchResult(store, season.ID, match.ID, match.AwayScore+3, match.HomeScore) // A home win whatever the original result
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after := ratings()
+	change := eloChange(played[[2]int{0, match.HomeTeamID}], played[[2]int{0, match.AwayTeamID}], edited.HomeScore, edited.AwayScore)
+	if got := after[[2]int{1, match.HomeTeamID}]; math.Abs(got-(played[[2]int{0, match.HomeTeamID}]+change)) > 1e-9 {
+		t.Errorf("home team after week 1 = %v, want %v", got, played[[2]int{0, match.HomeTeamID}]+change)
+	}
+
+	teams, err := store.Teams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	total, start := 0.0, 0.0
+	for _, team := range teams {
+		total += team.Elo
+		start += played[[2]int{0, team.ID}]
+		if math.Abs(team.Elo-after[[2]int{2, team.ID}]) > 1e-9 {
+			t.Errorf("%s has rating %v, want its rating after week 2, %v", team.Name, team.Elo, after[[2]int{2, team.ID}])
+		}
+	}
+	if math.Abs(total-start) > 1e-6 {
+		t.Errorf("ratings add up to %v, want the %v the season started with", total, start)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import ( // Import required packages:
+	"math"      // For powers and logarithms
+	"math/rand" // For generating random numbers
+	"time"      // For time-based default seeds
+)
+
+const (
+	averageGoals   = 1.35 // Expected goals per side when an attack meets a defense of equal strength
+	strengthWeight = 0.6  // How strongly the ratio of attack to defense shifts the expected goals
+
+	redCardScoring   = 0.7 // Factor a side's scoring rate is multiplied by for each of its players sent off
+	redCardConceding = 1.3 // Factor the opponent's scoring rate is multiplied by for each player sent off
+)
+
+type matchSide struct { // matchSide is a team as the match engine sees it
+	Attack        float64 // Attacking strength
+	Defense       float64 // Defensive strength
+	HomeAdvantage float64 // Factor the expected goals are raised by at home, e.g. 0.1 for 10% more
+}
+
+func teamSide(team Team, rules Rules) matchSide { // teamSide returns the side a team plays with; if the league plays by Elo, its attack and defense are scaled by its rating's strength over its fixed strength
+	form := 1.0
+	if rules.EloStrength {
+		form = eloStrength(team.Elo) / float64(max(team.Strength, 1)) // Above 1 for a team rated higher than its strength started it at
+	}
+	return matchSide{Attack: float64(team.Attack) * form, Defense: float64(team.Defense) * form, HomeAdvantage: float64(team.HomeAdvantage) / 100}
+}
+
+func expectedGoals(attack, opponentDefense float64) float64 { // expectedGoals returns the mean goals a team scores against an opponent, based on its attack and the opponent's defense
+	if attack <= 0 {
+		attack = 1 // Guard against missing strengths
+	}
+	if opponentDefense <= 0 {
+		opponentDefense = 1
+	}
+	return averageGoals * math.Pow(attack/opponentDefense, strengthWeight) // Stronger attacks score more, stronger defenses concede fewer
+}
+
+func newSeed() int64 { // newSeed returns a time-based seed for simulations that were not given one
+	return time.Now().UnixNano()
+}
+
+func weekRand(seed int64, week int) *rand.Rand { // weekRand returns the random source for one week, so a week plays the same whether simulated alone or in a full run
+	return rand.New(rand.NewSource(seed ^ int64(week)<<32))
+}
+
+func poissonGoals(rng *rand.Rand, lambda float64) int { // poissonGoals draws a goal count from a Poisson distribution with mean lambda (Knuth's method)
+	limit := math.Exp(-lambda)
+	goals := 0
+	for p := rng.Float64(); p > limit; p *= rng.Float64() { // Multiply uniform draws until the product falls below e^-lambda
+		goals++
+	}
+	return goals
+}
+
+func matchGoalRates(home, away matchSide) [2]float64 { // matchGoalRates returns the home and away sides' expected goals over a whole match; only the home side gains from playing at home
+	return [2]float64{
+		expectedGoals(home.Attack, away.Defense) * (1 + home.HomeAdvantage),
+		expectedGoals(away.Attack, home.Defense),
+	}
+}
+
+func simulateScore(rng *rand.Rand, home, away matchSide) (int, int) { // simulateScore draws a match's final score from each side's expected goals without playing its minutes, for predictions that keep no timeline
+	goalRates := matchGoalRates(home, away)
+	var goals [2]int
+	for minute := 0; minute < matchMinutes; { // Poisson goals for each stretch between red cards, at the rates of that stretch
+		card := nextRedCard(rng, minute)
+		played := float64(min(card, matchMinutes)-minute) / matchMinutes
+		for side := range goals {
+			goals[side] += poissonGoals(rng, goalRates[side]*played)
+		}
+		if card > matchMinutes {
+			break
+		}
+		side := rng.Intn(2) // Both sides are equally likely to be sent off
+		goalRates[side] *= redCardScoring
+		goalRates[1-side] *= redCardConceding
+		minute = card
+	}
+	return goals[0], goals[1]
+}
+
+func nextRedCard(rng *rand.Rand, minute int) int { // nextRedCard returns the minute of the next red card to either side after minute, past matchMinutes if there is none; its chance each minute is the same as in playMinutes
+	chance := 2 * redCardsPerSide / matchMinutes
+	return minute + 1 + int(math.Log(1-rng.Float64())/math.Log(1-chance)) // Geometric count of the minutes without one
+}
+
+func playMinutes(rng *rand.Rand, home, away matchSide, record func(minute int, eventType string, atHome bool)) (int, int) { // playMinutes plays a match minute by minute, passing each event to record, and returns the final score
+	goalRates := matchGoalRates(home, away) // Expected goals over a whole match at the sides' current rates
+	var goals, substitutions [2]int
+	for minute := 1; minute <= matchMinutes; minute++ {
+		draw := rng.Float64() * matchMinutes // One draw a minute, so a minute holds at most one event and each kind happens with its rate over the match length
+
+		substitutionRates := [2]float64{} // Substitutions are only made in the second half, up to the limit
+		if minute >= secondHalfMinute {
+			for side := range substitutionRates {
+				if substitutions[side] < maxSubstitutions {
+					substitutionRates[side] = substitutionsPerSide * matchMinutes / (matchMinutes - secondHalfMinute + 1)
+				}
+			}
+		}
+
+		chances := [...]struct { // Expected events of each kind over a whole match at this minute's rates
+			eventType string
+			side      int // 0 for the home side, 1 for the away side
+			rate      float64
+		}{
+			{eventGoal, 0, goalRates[0]}, {eventGoal, 1, goalRates[1]},
+			{eventRedCard, 0, redCardsPerSide}, {eventRedCard, 1, redCardsPerSide},
+			{eventYellowCard, 0, yellowCardsPerSide}, {eventYellowCard, 1, yellowCardsPerSide},
+			{eventSubstitution, 0, substitutionRates[0]}, {eventSubstitution, 1, substitutionRates[1]},
+		}
+
+		for _, chance := range chances {
+			if draw >= chance.rate {
+				draw -= chance.rate
+				continue
+			}
+			switch chance.eventType {
+			case eventGoal:
+				goals[chance.side]++
+			case eventRedCard: // The side plays the rest of the match a player down
+				goalRates[chance.side] *= redCardScoring
+				goalRates[1-chance.side] *= redCardConceding
+			case eventSubstitution:
+				substitutions[chance.side]++
+			}
+			record(minute, chance.eventType, chance.side == 0)
+			break
+		}
+	}
+	return goals[0], goals[1]
+}
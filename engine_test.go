@@ -0,0 +1,67 @@
+package main
+
+import ( // Import required packages:
+	"math"      // For comparing floating-point results
+	"math/rand" // For fixed random sources
+	"testing"   // For the test framework
+)
+
+func TestExpectedGoals(t *testing.T) { // Equal teams score the average, stronger teams more, and missing strengths count as 1
+	tests := []struct {
+		name                       string
+		strength, opponentStrength float64
+		want                       float64
+	}{
+		{"equal strengths", 3, 3, averageGoals},
+		{"strongest against weakest", 4, 1, averageGoals * math.Pow(4, strengthWeight)},
+		{"weakest against strongest", 1, 4, averageGoals * math.Pow(0.25, strengthWeight)},
+		{"missing strengths", 0, 0, averageGoals},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expectedGoals(tt.strength, tt.opponentStrength); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("expectedGoals(%v, %v) = %v, want %v", tt.strength, tt.opponentStrength, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPoissonGoals(t *testing.T) { // Goal counts have the mean and variance of a Poisson distribution
+	const draws = 200000
+	rng := rand.New(rand.NewSource(1))
+	for _, lambda := range []float64{0.5, averageGoals, 4} {
+		sum, sumSquares := 0.0, 0.0
+		for i := 0; i < draws; i++ {
+			goals := float64(poissonGoals(rng, lambda))
+			if goals < 0 {
+				t.Fatalf("poissonGoals(%v) = %v", lambda, goals)
+			}
+			sum += goals
+			sumSquares += goals * goals
+		}
+		mean := sum / draws
+		variance := sumSquares/draws - mean*mean
+		if math.Abs(mean-lambda) > 0.03*lambda+0.01 || math.Abs(variance-lambda) > 0.05*lambda+0.01 {
+			t.Errorf("poissonGoals(%v): mean %.3f and variance %.3f, want both near %v", lambda, mean, variance, lambda)
+		}
+	}
+}
+
+func TestSimulateScoreMatchesTimeline(t *testing.T) { // Predictions draw scores from the same model played matches use, so both score the same on average
+	const draws = 100000
+	home, away := matchSide{Attack: 4, Defense: 3, HomeAdvantage: 0.1}, matchSide{Attack: 2, Defense: 2}
+	rng := rand.New(rand.NewSource(1))
+	var drawn, played [2]float64
+	for i := 0; i < draws; i++ {
+		homeScore, awayScore := simulateScore(rng, home, away)
+		drawn[0], drawn[1] = drawn[0]+float64(homeScore), drawn[1]+float64(awayScore)
+		homeScore, awayScore = playMinutes(rng, home, away, func(int, string, bool) {})
+		played[0], played[1] = played[0]+float64(homeScore), played[1]+float64(awayScore)
+	}
+	for side, want := range matchGoalRates(home, away) {
+		if math.Abs(drawn[side]/draws-played[side]/draws) > 0.03 || math.Abs(drawn[side]/draws-want) > 0.05 {
+			t.Errorf("side %d: simulateScore averages %.3f goals and playMinutes %.3f, want both near %.3f", side, drawn[side]/draws, played[side]/draws, want)
+		}
+	}
+}
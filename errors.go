@@ -0,0 +1,107 @@
+package main
+
+import ( // Import required packages:
+	"context"       // For passing the response format to error helpers
+	"encoding/json" // For JSON error bodies
+	"errors"        // For matching error values
+	"fmt"           // For HTML error bodies
+	"html"          // For escaping messages in HTML error bodies
+	"log"           // For logging errors
+	"net/http"      // For HTTP request handling
+	"runtime/debug" // For logging the stack of a recovered panic
+)
+
+type responseFormat int // responseFormat is the body format a route answers with, used for its error responses
+
+const (
+	formatJSON responseFormat = iota // JSON endpoints
+	formatHTML                       // Endpoints returning HTML fragments for the Front-end
+)
+
+type formatKey struct{} // Context key holding a request's responseFormat
+
+type errorBody struct { // errorBody is the JSON body of every error response
+	Error struct {
+		Status  int    `json:"status"`  // HTTP status code
+		Code    string `json:"code"`    // Stable machine-readable error code, e.g. week_already_played
+		Message string `json:"message"` // Human-readable description
+	} `json:"error"`
+}
+
+var storeErrors = []struct { // Store errors that are the client's fault, with the status and code they are reported with
+	err    error
+	status int
+	code   string
+}{
+	{errLeagueNotFound, http.StatusNotFound, "league_not_found"},
+	{errNoSeason, http.StatusNotFound, "no_season"},
+	{errSeasonNotFound, http.StatusNotFound, "season_not_found"},
+	{errInvalidSeason, http.StatusBadRequest, "invalid_season"},
+	{errTeamNotFound, http.StatusNotFound, "team_not_found"},
+	{errInvalidTeam, http.StatusBadRequest, "invalid_team"},
+	{errInvalidImport, http.StatusBadRequest, "invalid_import"},
+	{errInvalidRules, http.StatusBadRequest, "invalid_rules"},
+	{errDuplicateTeam, http.StatusConflict, "duplicate_team"},
+	{errTeamsLocked, http.StatusConflict, "teams_locked"},
+	{errTooFewTeams, http.StatusConflict, "too_few_teams"},
+	{errMatchNotFound, http.StatusNotFound, "match_not_found"},
+	{errDeductionNotFound, http.StatusNotFound, "deduction_not_found"},
+	{errSeasonArchived, http.StatusConflict, "season_finished"},
+	{errMatchNotPlayed, http.StatusConflict, "match_not_played"},
+	{errWeekAlreadyPlayed, http.StatusConflict, "week_already_played"},
+	{errLeagueBusy, http.StatusConflict, "league_busy"},
+}
+
+func jsonHandler(h http.HandlerFunc) http.HandlerFunc { // jsonHandler wraps a JSON endpoint so its errors, including panics, are reported as JSON
+	return withFormat(formatJSON, h)
+}
+
+func htmlHandler(h http.HandlerFunc) http.HandlerFunc { // htmlHandler wraps an HTML endpoint so its errors, including panics, are reported as HTML
+	return withFormat(formatHTML, h)
+}
+
+func withFormat(format responseFormat, h http.HandlerFunc) http.HandlerFunc { // withFormat records the route's response format and recovers from panics in h
+	return func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithValue(r.Context(), formatKey{}, format))
+		defer func() {
+			if v := recover(); v != nil { // Report anything unexpected instead of dropping the connection
+				if v == http.ErrAbortHandler {
+					panic(v) // Let net/http abort the response as intended
+				}
+				log.Printf("panic serving %s: %v\n%s", r.URL.Path, v, debug.Stack())
+				writeError(w, r, http.StatusInternalServerError, "internal_error", "Internal server error")
+			}
+		}()
+		h(w, r)
+	}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) { // writeError sends an error response with a code, as JSON or HTML depending on the route
+	if format, _ := r.Context().Value(formatKey{}).(responseFormat); format == formatHTML {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("X-Error-Code", code)
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "<p class=\"error\" data-code=\"%s\">%s</p>\n", code, html.EscapeString(message))
+		return
+	}
+
+	var body errorBody
+	body.Error.Status = status
+	body.Error.Code = code
+	body.Error.Message = message
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Error-Code", code)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func storeError(w http.ResponseWriter, r *http.Request, err error, message string) { // storeError reports a failed store operation: known errors with their own status and code, anything else as a logged 500 with message
+	for _, known := range storeErrors {
+		if errors.Is(err, known.err) {
+			writeError(w, r, known.status, known.code, err.Error())
+			return
+		}
+	}
+	log.Println(err)
+	writeError(w, r, http.StatusInternalServerError, "internal_error", message)
+}
@@ -0,0 +1,78 @@
+package main
+
+import ( // Import required packages:
+	"encoding/json"     // For decoding error bodies
+	"errors"            // For creating error values
+	"fmt"               // For wrapping errors
+	"net/http"          // For HTTP status codes
+	"net/http/httptest" // For recording responses
+	"strings"           // For checking HTML bodies
+	"testing"           // For the test framework
+)
+
+func TestStoreError(t *testing.T) { // Known store errors keep their own status and code, even when wrapped, and anything else is a 500
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"no season", errNoSeason, http.StatusNotFound, "no_season"},
+		{"unknown season", errSeasonNotFound, http.StatusNotFound, "season_not_found"},
+		{"invalid season", errInvalidSeason, http.StatusBadRequest, "invalid_season"},
+		{"unknown league", errLeagueNotFound, http.StatusNotFound, "league_not_found"},
+		{"unknown team", errTeamNotFound, http.StatusNotFound, "team_not_found"},
+		{"invalid team", fmt.Errorf("%w: name must be 1 to 30 characters", errInvalidTeam), http.StatusBadRequest, "invalid_team"},
+		{"duplicate team", errDuplicateTeam, http.StatusConflict, "duplicate_team"},
+		{"teams locked", errTeamsLocked, http.StatusConflict, "teams_locked"},
+		{"too few teams", errTooFewTeams, http.StatusConflict, "too_few_teams"},
+		{"unknown match", fmt.Errorf("edit: %w", errMatchNotFound), http.StatusNotFound, "match_not_found"},
+		{"unplayed match", errMatchNotPlayed, http.StatusConflict, "match_not_played"},
+		{"week already played", errWeekAlreadyPlayed, http.StatusConflict, "week_already_played"},
+		{"invalid rules", errInvalidRules, http.StatusBadRequest, "invalid_rules"},
+		{"unknown deduction", errDeductionNotFound, http.StatusNotFound, "deduction_not_found"},
+		{"season archived", errSeasonArchived, http.StatusConflict, "season_finished"},
+		{"league busy", errLeagueBusy, http.StatusConflict, "league_busy"},
+		{"unexpected error", errors.New("disk I/O error"), http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			jsonHandler(func(w http.ResponseWriter, r *http.Request) {
+				storeError(w, r, tt.err, "Failed to load")
+			})(w, httptest.NewRequest(http.MethodGet, "/api/table", nil))
+
+			var body errorBody
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.wantStatus || body.Error.Status != tt.wantStatus || body.Error.Code != tt.wantCode || w.Header().Get("X-Error-Code") != tt.wantCode {
+				t.Errorf("got %d %+v, want %d %s", w.Code, body.Error, tt.wantStatus, tt.wantCode)
+			}
+			if tt.wantStatus == http.StatusInternalServerError && body.Error.Message != "Failed to load" {
+				t.Errorf("message = %q, want the handler's message rather than the internal error", body.Error.Message)
+			}
+		})
+	}
+}
+
+func TestErrorFormats(t *testing.T) { // HTML routes answer errors with an escaped HTML fragment, and panics become a 500 in the route's format
+	w := httptest.NewRecorder()
+	htmlHandler(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, r, http.StatusBadRequest, "invalid_week", "Invalid week <b>")
+	})(w, httptest.NewRequest(http.MethodGet, "/simulate", nil))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `data-code="invalid_week"`) || !strings.Contains(w.Body.String(), "Invalid week &lt;b&gt;") {
+		t.Errorf("HTML error = %d %q", w.Code, w.Body.String())
+	}
+
+	for _, wrap := range []func(http.HandlerFunc) http.HandlerFunc{jsonHandler, htmlHandler} {
+		w := httptest.NewRecorder()
+		wrap(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusInternalServerError || w.Header().Get("X-Error-Code") != "internal_error" {
+			t.Errorf("panic answered with %d %q, want 500 internal_error", w.Code, w.Header().Get("X-Error-Code"))
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import ( // Import required packages:
+	"fmt"       // For formatted timelines
+	"html"      // For escaping team names
+	"math/rand" // For drawing event minutes
+	"net/http"  // For HTTP request handling
+	"sort"      // For ordering events by minute
+	"strconv"   // For converting strings to integers
+	"strings"   // For joining goal minutes
+)
+
+const ( // Types of match events
+	eventGoal         = "goal"         // A goal scored by the team
+	eventYellowCard   = "yellow_card"  // A player of the team booked
+	eventRedCard      = "red_card"     // A player of the team sent off
+	eventSubstitution = "substitution" // A player of the team substituted
+)
+
+const (
+	matchMinutes         = 90  // Length of a match; events fall in minutes 1-90
+	secondHalfMinute     = 46  // First minute of the second half, when substitutions start
+	yellowCardsPerSide   = 1.8 // Average yellow cards shown to each side
+	redCardsPerSide      = 0.1 // Average red cards shown to each side
+	substitutionsPerSide = 4.0 // Average substitutions a side makes, all in the second half
+	maxSubstitutions     = 5   // Most substitutions a side makes
+)
+
+type MatchEvent struct { // MatchEvent is one event of a played match's timeline
+	MatchID int    `json:"matchId"` // Match the event happened in
+	Minute  int    `json:"minute"`  // Minute of the match (1-90)
+	Type    string `json:"type"`    // goal, yellow_card, red_card or substitution
+	TeamID  int    `json:"teamId"`  // Team the event belongs to
+	Team    string `json:"team"`    // Team name
+}
+
+func simulateMatch(rng *rand.Rand, match Match, home, away matchSide) (Match, []MatchEvent) { // simulateMatch plays a fixture minute by minute and returns it with the score its goal events add up to, and its timeline
+	var events []MatchEvent
+	match.HomeScore, match.AwayScore = playMinutes(rng, home, away, func(minute int, eventType string, atHome bool) {
+		teamID := match.AwayTeamID
+		if atHome {
+			teamID = match.HomeTeamID
+		}
+		events = append(events, MatchEvent{MatchID: match.ID, Minute: minute, Type: eventType, TeamID: teamID}) // Events come in minute order
+	})
+	return match, events
+}
+
+func moveGoalEvents(rng *rand.Rand, match Match, events []MatchEvent) []MatchEvent { // moveGoalEvents fits a timeline to a corrected score: cards and substitutions stay, a side's latest goals are dropped when it scored fewer, and extra goals go to random minutes without events
+	var moved []MatchEvent
+	used := make(map[int]bool) // Minutes holding an event that stays
+	goals := map[int]int{match.HomeTeamID: match.HomeScore, match.AwayTeamID: match.AwayScore}
+	for _, event := range events { // Events are in minute order, so the goals kept are each side's earliest
+		if event.Type == eventGoal {
+			if goals[event.TeamID] == 0 {
+				continue
+			}
+			goals[event.TeamID]--
+		}
+		moved = append(moved, event)
+		used[event.Minute] = true
+	}
+
+	for _, teamID := range []int{match.HomeTeamID, match.AwayTeamID} { // Goals added by the correction
+		for ; goals[teamID] > 0; goals[teamID]-- {
+			var free []int
+			for minute := 1; minute <= matchMinutes; minute++ {
+				if !used[minute] {
+					free = append(free, minute)
+				}
+			}
+			minute := 1 + rng.Intn(matchMinutes) // Every minute holds an event already, so the goal shares one
+			if len(free) > 0 {
+				minute = free[rng.Intn(len(free))]
+			}
+			moved = append(moved, MatchEvent{MatchID: match.ID, Minute: minute, Type: eventGoal, TeamID: teamID})
+			used[minute] = true
+		}
+	}
+
+	sort.SliceStable(moved, func(i, j int) bool { // Timeline order
+		return moved[i].Minute < moved[j].Minute
+	})
+	return moved
+}
+
+func goalTimelineHTML(match Match, events []MatchEvent) string { // goalTimelineHTML returns the minutes a match's goals were scored in, e.g. 12' Chelsea, 67' Arsenal, or nothing for a match without goals or timeline
+	var goals []string
+	for _, event := range events {
+		if event.MatchID != match.ID || event.Type != eventGoal {
+			continue
+		}
+		team := match.HomeTeam
+		if event.TeamID == match.AwayTeamID {
+			team = match.AwayTeam
+		}
+		goals = append(goals, fmt.Sprintf("%d' %s", event.Minute, html.EscapeString(team)))
+	}
+	if len(goals) == 0 {
+		return ""
+	}
+	return "<small>  Goals: " + strings.Join(goals, ", ") + "</small>\n"
+}
+
+func (srv *server) eventsAPIHandler(w http.ResponseWriter, r *http.Request) { // eventsAPIHandler sends the timeline events of a week's matches, or of the whole season, as JSON (current season unless season is given)
+	_, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	week := 0 // Default to every week when no week is given
+	if weekStr := r.URL.Query().Get("week"); weekStr != "" {
+		var err error
+		week, err = strconv.Atoi(weekStr) // Convert week from string to int
+		if err != nil || week < 1 {
+			writeError(w, r, http.StatusBadRequest, "invalid_week", "Invalid week parameter") // Return error for invalid week
+			return
+		}
+	}
+
+	seasonID, err := requestSeasonID(store, r) // Season to list, defaulting to the current one
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch season") // 400 for an invalid season, 404 for a season the league does not have or before its first season
+		return
+	}
+
+	events, err := store.Events(seasonID, week)
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch match events") // Return error if query fails
+		return
+	}
+
+	writeJSON(w, events)
+}
@@ -0,0 +1,59 @@
+package main
+
+import ( // Import required packages:
+	"math/rand" // For a fixed random source
+	"testing"   // For the test framework
+)
+
+func TestMoveGoalEvents(t *testing.T) { // A corrected score keeps the cards, substitutions and earliest goals of the timeline, and its goals add up to the new score
+	match := Match{ID: 1, HomeTeamID: 1, AwayTeamID: 2}
+	timeline := []MatchEvent{
+		{MatchID: 1, Minute: 10, Type: eventGoal, TeamID: 1},
+		{MatchID: 1, Minute: 30, Type: eventYellowCard, TeamID: 2},
+		{MatchID: 1, Minute: 55, Type: eventGoal, TeamID: 1},
+		{MatchID: 1, Minute: 60, Type: eventSubstitution, TeamID: 1},
+		{MatchID: 1, Minute: 80, Type: eventGoal, TeamID: 2},
+	}
+
+	tests := []struct {
+		name                 string
+		homeScore, awayScore int
+	}{
+		{"same score", 2, 1},
+		{"fewer goals", 1, 0},
+		{"more goals", 4, 3},
+		{"no goals", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match.HomeScore, match.AwayScore = tt.homeScore, tt.awayScore
+			moved := moveGoalEvents(rand.New(rand.NewSource(1)), match, timeline)
+
+			goals := make(map[int][]int) // Goal minutes of each team
+			others := 0                  // Cards and substitutions
+			for i, event := range moved {
+				if i > 0 && event.Minute < moved[i-1].Minute {
+					t.Errorf("timeline out of order: %+v", moved)
+				}
+				if event.Minute < 1 || event.Minute > matchMinutes {
+					t.Errorf("event outside the match: %+v", event)
+				}
+				if event.Type == eventGoal {
+					goals[event.TeamID] = append(goals[event.TeamID], event.Minute)
+				} else {
+					others++
+				}
+			}
+			if len(goals[1]) != tt.homeScore || len(goals[2]) != tt.awayScore {
+				t.Errorf("timeline has %d-%d goals, want %d-%d", len(goals[1]), len(goals[2]), tt.homeScore, tt.awayScore)
+			}
+			if others != 2 {
+				t.Errorf("timeline kept %d cards and substitutions, want 2", others)
+			}
+			if tt.homeScore >= 1 && goals[1][0] != 10 {
+				t.Errorf("home goals at %v, want the first one kept at 10'", goals[1])
+			}
+		})
+	}
+}
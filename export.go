@@ -0,0 +1,121 @@
+package main
+
+import ( // Import required packages:
+	"encoding/csv" // For CSV downloads
+	"fmt"          // For formatted file names
+	"log"          // For logging write errors
+	"net/http"     // For HTTP request handling
+	"strconv"      // For converting integers to strings
+)
+
+func (srv *server) exportTableHandler(w http.ResponseWriter, r *http.Request) { // exportTableHandler sends a season's league table as a CSV or JSON download (current season unless season is given)
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	_, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	seasonID, err := requestSeasonID(store, r) // Season to export, defaulting to the current one
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch season") // 400 for an invalid season, 404 for a season the league does not have or before its first season
+		return
+	}
+
+	teams, err := seasonTable(store, seasonID)
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch league table") // Return error if query fails
+		return
+	}
+
+	setDownload(w, fmt.Sprintf("table-season-%d.%s", seasonID, format))
+	if format == "json" {
+		writeJSON(w, teams)
+		return
+	}
+
+	rows := [][]string{{"team", "short_code", "played", "won", "drawn", "lost", "gf", "ga", "gd", "points", "strength", "attack", "defense", "home_advantage"}} // Header row
+	for _, team := range teams {
+		rows = append(rows, []string{team.Name, team.ShortCode, strconv.Itoa(team.Played), strconv.Itoa(team.Won), strconv.Itoa(team.Drawn), strconv.Itoa(team.Lost),
+			strconv.Itoa(team.GF), strconv.Itoa(team.GA), strconv.Itoa(team.GD), strconv.Itoa(team.Points), strconv.Itoa(team.Strength),
+			strconv.Itoa(team.Attack), strconv.Itoa(team.Defense), strconv.Itoa(team.HomeAdvantage)})
+	}
+	writeCSV(w, rows)
+}
+
+func (srv *server) exportMatchesHandler(w http.ResponseWriter, r *http.Request) { // exportMatchesHandler sends every match of a season as a CSV or JSON download (current season unless season is given)
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	_, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	seasonID, err := requestSeasonID(store, r) // Season to export, defaulting to the current one
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch season") // 400 for an invalid season, 404 for a season the league does not have or before its first season
+		return
+	}
+
+	matches, err := store.Matches(seasonID, 0) // Every week, including unplayed fixtures
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch matches") // Return error if query fails
+		return
+	}
+
+	setDownload(w, fmt.Sprintf("matches-season-%d.%s", seasonID, format))
+	if format == "json" {
+		writeJSON(w, matches)
+		return
+	}
+
+	rows := [][]string{{"week", "home", "away", "home_score", "away_score"}} // Header row, in the format accepted by the fixtures import
+	for _, match := range matches {
+		homeScore, awayScore := "", "" // Left empty for fixtures that have not been played
+		if match.Played {
+			homeScore, awayScore = strconv.Itoa(match.HomeScore), strconv.Itoa(match.AwayScore)
+		}
+		rows = append(rows, []string{strconv.Itoa(match.Week), match.HomeTeam, match.AwayTeam, homeScore, awayScore})
+	}
+	writeCSV(w, rows)
+}
+
+func exportFormat(w http.ResponseWriter, r *http.Request) (string, bool) { // exportFormat returns the format parameter, csv by default, rejecting anything but csv and json
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "csv":
+		return "csv", true
+	case "json":
+		return "json", true
+	default:
+		writeError(w, r, http.StatusBadRequest, "invalid_format", "Invalid format parameter, use csv or json") // Return error for unknown formats
+		return "", false
+	}
+}
+
+func seasonTable(store LeagueStore, seasonID int) ([]Team, error) { // seasonTable returns the live table of the current season, or the archived final standings of an earlier one, in table order
+	current, err := store.Season()
+	if err != nil {
+		return nil, err
+	}
+	if current.ID == seasonID {
+		return leagueTable(store)
+	}
+	return archivedTable(store, seasonID)
+}
+
+func setDownload(w http.ResponseWriter, filename string) { // setDownload makes browsers save the response as a file
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+}
+
+func writeCSV(w http.ResponseWriter, rows [][]string) { // writeCSV encodes rows as the CSV response body
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	if err := csv.NewWriter(w).WriteAll(rows); err != nil {
+		log.Println(err) // The status has already been sent, so the error can only be logged
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import ( // Import required packages:
+	"encoding/csv"      // For reading CSV downloads
+	"encoding/json"     // For decoding JSON downloads
+	"net/http"          // For HTTP status codes
+	"net/http/httptest" // For recording responses
+	"strings"           // For checking response headers
+	"testing"           // For the test framework
+)
+
+func TestExportMatches(t *testing.T) { // The matches CSV lists every fixture with scores for played ones, in the format the fixtures import reads back
+	srv := newTestServer(t, "Chelsea", "Arsenal", "Everton")
+	htmlHandler(srv.simulateHandler)(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/simulate?seed=1", nil))
+
+	w := httptest.NewRecorder()
+	jsonHandler(srv.exportMatchesHandler)(w, httptest.NewRequest(http.MethodGet, "/api/export/matches", nil))
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") || !strings.Contains(w.Header().Get("Content-Disposition"), "matches-season-1.csv") {
+		t.Fatalf("export = %d %q %q", w.Code, w.Header().Get("Content-Type"), w.Header().Get("Content-Disposition"))
+	}
+	data := w.Body.Bytes()
+
+	rows, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 7 || strings.Join(rows[0], ",") != "week,home,away,home_score,away_score" {
+		t.Fatalf("export has %d rows starting with %v, want a header and 6 fixtures", len(rows), rows[0])
+	}
+
+	fixtures, err := decodeFixturesCSV(data)
+	if err != nil {
+		t.Fatalf("export cannot be imported: %v", err)
+	}
+	for _, fixture := range fixtures {
+		if fixture.played() != (fixture.Week == 1) {
+			t.Errorf("week %d fixture %s v %s played = %v, want only week 1 played", fixture.Week, fixture.Home, fixture.Away, fixture.played())
+		}
+	}
+}
+
+func TestExportTable(t *testing.T) { // The table downloads as JSON or CSV, and other formats are rejected
+	srv := newTestServer(t, "Chelsea", "Arsenal")
+	export := jsonHandler(srv.exportTableHandler)
+
+	w := httptest.NewRecorder()
+	export(w, httptest.NewRequest(http.MethodGet, "/api/export/table?format=json", nil))
+	var teams []Team
+	if err := json.NewDecoder(w.Body).Decode(&teams); err != nil || w.Code != http.StatusOK || len(teams) != 2 {
+		t.Errorf("JSON export = %d %+v, %v, want 2 teams", w.Code, teams, err)
+	}
+
+	w = httptest.NewRecorder()
+	export(w, httptest.NewRequest(http.MethodGet, "/api/export/table", nil))
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil || len(rows) != 3 || rows[0][0] != "team" {
+		t.Errorf("CSV export = %v, %v, want a header and 2 teams", rows, err)
+	}
+
+	w = httptest.NewRecorder()
+	export(w, httptest.NewRequest(http.MethodGet, "/api/export/table?format=xml", nil))
+	if w.Code != http.StatusBadRequest || w.Header().Get("X-Error-Code") != "invalid_format" {
+		t.Errorf("XML export answered %d %q, want 400 invalid_format", w.Code, w.Header().Get("X-Error-Code"))
+	}
+}
+
+func TestUnknownSeason(t *testing.T) { // Seasons the league does not have are answered with 404 instead of an empty download
+	srv := newTestServer(t, "Chelsea", "Arsenal")
+	empty := &server{storage: newMemoryStorage()} // The default league before its first season
+	handlers := map[string]http.HandlerFunc{
+		"/api/export/table":   srv.exportTableHandler,
+		"/api/export/matches": srv.exportMatchesHandler,
+		"/api/matches":        srv.matchesAPIHandler,
+		"/api/standings":      srv.standingsAPIHandler,
+		"/api/ratings":        srv.ratingsAPIHandler,
+		"/api/events":         srv.eventsAPIHandler,
+	}
+	for path, handler := range handlers {
+		for _, tt := range []struct {
+			query      string
+			wantStatus int
+			wantCode   string
+		}{
+			{"?season=99", http.StatusNotFound, "season_not_found"},
+			{"?season=x", http.StatusBadRequest, "invalid_season"},
+		} {
+			w := httptest.NewRecorder()
+			jsonHandler(handler)(w, httptest.NewRequest(http.MethodGet, path+tt.query, nil))
+			if w.Code != tt.wantStatus || w.Header().Get("X-Error-Code") != tt.wantCode {
+				t.Errorf("%s%s answered %d %q, want %d %s", path, tt.query, w.Code, w.Header().Get("X-Error-Code"), tt.wantStatus, tt.wantCode)
+			}
+		}
+	}
+
+	for _, handler := range []http.HandlerFunc{empty.exportTableHandler, empty.exportMatchesHandler, empty.matchesAPIHandler} {
+		w := httptest.NewRecorder()
+		jsonHandler(handler)(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusNotFound || w.Header().Get("X-Error-Code") != "no_season" {
+			t.Errorf("league without a season answered %d %q, want 404 no_season", w.Code, w.Header().Get("X-Error-Code"))
+		}
+	}
+}
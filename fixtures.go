@@ -0,0 +1,79 @@
+package main
+
+import ( // Import required packages:
+	"math/rand" // For shuffling the team order
+)
+
+type Fixture struct { // Fixture represents a scheduled pairing of two teams in a given week
+	HomeTeamID int // Home team ID
+	AwayTeamID int // Away team ID
+	Week       int // Week of fixture
+}
+
+const byeTeamID = 0 // Placeholder team ID used to give a team a bye week when the team count is odd
+
+func seasonLength(teamCount int) int { // seasonLength returns the number of weeks in a home-and-away season for the given team count
+	if teamCount < 2 {
+		return 0 // No season possible with fewer than 2 teams
+	}
+	if teamCount%2 == 1 {
+		teamCount++ // Odd team counts are padded with a bye slot
+	}
+	return 2 * (teamCount - 1)
+}
+
+func roundRobinSchedule(teamIDs []int) []Fixture { // roundRobinSchedule builds a double round-robin schedule using the circle (Berger) method
+	if len(teamIDs) < 2 {
+		return nil // No fixtures possible with fewer than 2 teams
+	}
+
+	circle := make([]int, len(teamIDs)) // Copy team IDs so the caller's slice is not rotated
+	copy(circle, teamIDs)
+	if len(circle)%2 == 1 {
+		circle = append(circle, byeTeamID) // Pad odd team counts so one team sits out each week
+	}
+	n := len(circle)
+
+	rounds := n - 1 // Each team meets every other team once per half-season
+	var fixtures []Fixture
+	for round := 0; round < rounds; round++ {
+		for i := 0; i < n/2; i++ { // Pair the i-th team from the front with the i-th team from the back
+			home, away := circle[i], circle[n-1-i]
+			if home == byeTeamID || away == byeTeamID {
+				continue // The team paired with the bye slot does not play this week
+			}
+			if (i == 0 && round%2 == 1) || (i > 0 && i%2 == 1) { // Alternate home and away so no team is always at home
+				home, away = away, home
+			}
+			fixtures = append(fixtures, Fixture{HomeTeamID: home, AwayTeamID: away, Week: round + 1})
+		}
+
+		last := circle[n-1] // Rotate every team except the first one place clockwise
+		copy(circle[2:], circle[1:n-1])
+		circle[1] = last
+	}
+
+	firstHalf := len(fixtures)
+	for i := 0; i < firstHalf; i++ { // Second half mirrors the first with home and away reversed
+		fixture := fixtures[i]
+		fixtures = append(fixtures, Fixture{HomeTeamID: fixture.AwayTeamID, AwayTeamID: fixture.HomeTeamID, Week: fixture.Week + rounds})
+	}
+
+	return fixtures
+}
+
+func GenerateFixtures(store LeagueStore, seasonID int, rng *rand.Rand) error { // GenerateFixtures schedules a full home-and-away season and stores it as unplayed matches
+	teams, err := store.Teams() // Retrieve teams in a stable order
+	if err != nil {
+		return err
+	}
+
+	teamIDs := make([]int, len(teams))
+	for i, team := range teams {
+		teamIDs[i] = team.ID // Add team ID to list
+	}
+
+	rng.Shuffle(len(teamIDs), func(i, j int) { teamIDs[i], teamIDs[j] = teamIDs[j], teamIDs[i] }) // Shuffle so each season has a different fixture order
+
+	return store.AddFixtures(seasonID, roundRobinSchedule(teamIDs)) // Insert each fixture as an unplayed match
+}
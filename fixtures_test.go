@@ -0,0 +1,89 @@
+package main
+
+import ( // Import required packages:
+	"testing" // For the test framework
+)
+
+func TestRoundRobinSchedule(t *testing.T) { // Every pair meets once at each home, and no team plays twice in a week
+	tests := []struct {
+		name    string
+		teamIDs []int
+	}{
+		{"two teams", []int{1, 2}},
+		{"three teams with byes", []int{1, 2, 3}},
+		{"four teams", []int{1, 2, 3, 4}},
+		{"seven teams with byes", []int{1, 2, 3, 4, 5, 6, 7}},
+		{"eight teams", []int{1, 2, 3, 4, 5, 6, 7, 8}},
+		{"gaps in team IDs", []int{3, 9, 14, 20, 21}},
+		{"twenty teams", []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixtures := roundRobinSchedule(tt.teamIDs)
+			n := len(tt.teamIDs)
+			if want := n * (n - 1); len(fixtures) != want {
+				t.Fatalf("got %d fixtures, want %d", len(fixtures), want)
+			}
+
+			known := make(map[int]bool) // Teams of the league
+			for _, id := range tt.teamIDs {
+				known[id] = true
+			}
+			meetings := make(map[[2]int]int) // Number of times each home team hosts each away team
+			busy := make(map[[2]int]bool)    // Teams already playing in a week
+			weeks := seasonLength(n)
+			for _, fixture := range fixtures {
+				if !known[fixture.HomeTeamID] || !known[fixture.AwayTeamID] || fixture.HomeTeamID == fixture.AwayTeamID {
+					t.Fatalf("invalid fixture %+v", fixture)
+				}
+				if fixture.Week < 1 || fixture.Week > weeks {
+					t.Fatalf("fixture %+v outside weeks 1-%d", fixture, weeks)
+				}
+				for _, id := range []int{fixture.HomeTeamID, fixture.AwayTeamID} {
+					if busy[[2]int{fixture.Week, id}] {
+						t.Fatalf("team %d plays twice in week %d", id, fixture.Week)
+					}
+					busy[[2]int{fixture.Week, id}] = true
+				}
+				meetings[[2]int{fixture.HomeTeamID, fixture.AwayTeamID}]++
+			}
+
+			for _, home := range tt.teamIDs {
+				for _, away := range tt.teamIDs {
+					if home != away && meetings[[2]int{home, away}] != 1 {
+						t.Errorf("team %d hosts team %d %d times, want once", home, away, meetings[[2]int{home, away}])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRoundRobinScheduleTooFewTeams(t *testing.T) { // No fixtures without two teams
+	for _, teamIDs := range [][]int{nil, {1}} {
+		if fixtures := roundRobinSchedule(teamIDs); len(fixtures) != 0 {
+			t.Errorf("roundRobinSchedule(%v) = %d fixtures, want none", teamIDs, len(fixtures))
+		}
+	}
+}
+
+func TestSeasonLength(t *testing.T) { // Two rounds of n-1 weeks, with odd team counts padded by a bye
+	tests := []struct {
+		teams, weeks int
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 2},
+		{3, 6},
+		{4, 6},
+		{7, 14},
+		{20, 38},
+	}
+
+	for _, tt := range tests {
+		if got := seasonLength(tt.teams); got != tt.weeks {
+			t.Errorf("seasonLength(%d) = %d, want %d", tt.teams, got, tt.weeks)
+		}
+	}
+}
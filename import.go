@@ -0,0 +1,452 @@
+package main
+
+import ( // Import required packages:
+	"bytes"         // For telling JSON from CSV input
+	"encoding/csv"  // For CSV files
+	"encoding/json" // For JSON files
+	"errors"        // For creating error values
+	"flag"          // For the import command's flags
+	"fmt"           // For formatted errors
+	"io"            // For reading request bodies and files
+	"log"           // For reporting the import
+	"net/http"      // For HTTP request handling
+	"os"            // For reading files given to the import command
+	"slices"        // For checking CSV headers
+	"sort"          // For ordering fixtures by week
+	"strconv"       // For converting strings to integers
+	"strings"       // For matching CSV headers and team names
+)
+
+var errInvalidImport = errors.New("invalid import") // Wrapped with the row or field that cannot be imported
+
+const maxImportSize = 1 << 20 // Largest import accepted by POST /api/import, in bytes
+
+type importFile struct { // importFile is a league to import: its teams and, optionally, its fixtures with or without results
+	Teams    []importTeam    `json:"teams"`    // Teams of the league
+	Fixtures []importFixture `json:"fixtures"` // Fixtures of the season; generated when the season starts if empty
+}
+
+type importTeam struct { // importTeam is one team of an import
+	Name      string `json:"name"`      // Team name
+	ShortCode string `json:"shortCode"` // Short code, derived from the name when empty
+	strengthsInput
+}
+
+type importFixture struct { // importFixture is one fixture of an import; both scores are given for an already-played match
+	Week      int    `json:"week"`      // Week of the fixture
+	Home      string `json:"home"`      // Home team name or short code
+	Away      string `json:"away"`      // Away team name or short code
+	HomeScore *int   `json:"homeScore"` // Home team score, if played
+	AwayScore *int   `json:"awayScore"` // Away team score, if played
+}
+
+func (f importFixture) played() bool { // played reports whether the fixture comes with a result
+	return f.HomeScore != nil
+}
+
+func (srv *server) importHandler(w http.ResponseWriter, r *http.Request) { // importHandler replaces a league's teams, and optionally its fixtures and results, with an uploaded JSON or CSV file
+	if r.Method != http.MethodPost {
+		writeError(w, r, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed") // Only POST imports
+		return
+	}
+
+	seed, err := parseSeasonSeed(r) // Seed for fixtures generated when the file has none, overridable with the seed parameter
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_seed", "Invalid seed parameter") // Return error for invalid seed
+		return
+	}
+
+	file, err := readImportRequest(w, r)
+	if err != nil {
+		storeError(w, r, err, "Failed to read import") // 400 for files that cannot be parsed
+		return
+	}
+
+	league, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	unlock, ok := srv.lockLeague(w, r, league.ID) // Only one request may change the league at a time
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	season, err := importLeague(store, file, seed)
+	if err != nil {
+		storeError(w, r, err, "Failed to import league") // 400 for invalid teams or fixtures
+		return
+	}
+
+	w.Header().Set("X-Simulation-Seed", strconv.FormatInt(seed, 10)) // Report the seed used
+	writeJSON(w, season)
+}
+
+func readImportRequest(w http.ResponseWriter, r *http.Request) (importFile, error) { // readImportRequest reads an import from a JSON or CSV body, or from the teams and fixtures files of a multipart form
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxImportSize); err != nil {
+			return importFile{}, fmt.Errorf("%w: %v", errInvalidImport, err)
+		}
+		teams, err := formFile(r, "teams")
+		if err != nil || teams == nil {
+			return importFile{}, fmt.Errorf("%w: a teams file is required", errInvalidImport)
+		}
+		fixtures, err := formFile(r, "fixtures") // Optional
+		if err != nil {
+			return importFile{}, fmt.Errorf("%w: %v", errInvalidImport, err)
+		}
+		return decodeImport(teams, fixtures)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return importFile{}, fmt.Errorf("%w: %v", errInvalidImport, err)
+	}
+	return decodeImport(body, nil)
+}
+
+func formFile(r *http.Request, field string) ([]byte, error) { // formFile returns the contents of an uploaded file, or nil if the field is missing
+	file, _, err := r.FormFile(field)
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+func decodeImport(teams, fixtures []byte) (importFile, error) { // decodeImport parses a JSON import, or a teams CSV with an optional fixtures CSV
+	if trimmed := bytes.TrimSpace(teams); len(trimmed) > 0 && trimmed[0] == '{' { // JSON holds both teams and fixtures
+		var file importFile
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.DisallowUnknownFields() // Catch misspelled fields instead of silently importing zeros
+		if err := decoder.Decode(&file); err != nil {
+			return importFile{}, fmt.Errorf("%w: %v", errInvalidImport, err)
+		}
+		if len(fixtures) > 0 {
+			return importFile{}, fmt.Errorf("%w: a JSON import holds its own fixtures", errInvalidImport)
+		}
+		return file, nil
+	}
+
+	var file importFile
+	var err error
+	if file.Teams, err = decodeTeamsCSV(teams); err != nil {
+		return importFile{}, err
+	}
+	if len(fixtures) > 0 {
+		if file.Fixtures, err = decodeFixturesCSV(fixtures); err != nil {
+			return importFile{}, err
+		}
+	}
+	return file, nil
+}
+
+func readCSV(data []byte, required, optional []string) ([]map[string]string, error) { // readCSV reads a CSV file with a header row into one map per row, keyed by column name
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // Byte order mark written by spreadsheet programs
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidImport, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: empty file", errInvalidImport)
+	}
+
+	header := records[0] // Column names, in any order
+	known := make(map[string]bool)
+	for _, column := range append(append([]string{}, required...), optional...) {
+		known[column] = true
+	}
+	for i, column := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(column))
+		if !known[header[i]] {
+			return nil, fmt.Errorf("%w: unknown column %q", errInvalidImport, column)
+		}
+	}
+	for _, column := range required {
+		if !slices.Contains(header, column) {
+			return nil, fmt.Errorf("%w: missing column %q", errInvalidImport, column)
+		}
+	}
+
+	var rows []map[string]string
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[column] = strings.TrimSpace(record[i])
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func decodeTeamsCSV(data []byte) ([]importTeam, error) { // decodeTeamsCSV parses a teams CSV with the columns name, strength and optionally short_code, attack, defense and home_advantage
+	rows, err := readCSV(data, []string{"name", "strength"}, []string{"short_code", "attack", "defense", "home_advantage"})
+	if err != nil {
+		return nil, err
+	}
+
+	var teams []importTeam
+	for i, row := range rows {
+		team := importTeam{Name: row["name"], ShortCode: row["short_code"]}
+		for _, field := range []struct {
+			column string
+			value  **int
+		}{{"strength", &team.Strength}, {"attack", &team.Attack}, {"defense", &team.Defense}, {"home_advantage", &team.HomeAdvantage}} {
+			if row[field.column] == "" && field.column != "strength" {
+				continue // Attack and defense follow the strength, and there is no home advantage, when left empty
+			}
+			value, err := strconv.Atoi(row[field.column])
+			if err != nil {
+				return nil, fmt.Errorf("%w: row %d: invalid %s %q", errInvalidImport, i+2, field.column, row[field.column])
+			}
+			*field.value = &value
+		}
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+func decodeFixturesCSV(data []byte) ([]importFixture, error) { // decodeFixturesCSV parses a fixtures CSV with the columns week, home, away and optionally home_score and away_score (empty for unplayed fixtures)
+	rows, err := readCSV(data, []string{"week", "home", "away"}, []string{"home_score", "away_score"})
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []importFixture
+	for i, row := range rows {
+		week, err := strconv.Atoi(row["week"])
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: invalid week %q", errInvalidImport, i+2, row["week"])
+		}
+		fixture := importFixture{Week: week, Home: row["home"], Away: row["away"]}
+		for _, score := range []struct {
+			column string
+			value  **int
+		}{{"home_score", &fixture.HomeScore}, {"away_score", &fixture.AwayScore}} {
+			if row[score.column] == "" {
+				continue // Not played yet
+			}
+			goals, err := strconv.Atoi(row[score.column])
+			if err != nil {
+				return nil, fmt.Errorf("%w: row %d: invalid %s %q", errInvalidImport, i+2, score.column, row[score.column])
+			}
+			*score.value = &goals
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures, nil
+}
+
+func checkImport(file importFile) ([]Team, []importFixture, int, error) { // checkImport validates an import and returns its teams, its fixtures in week order and the last week with results
+	var teams []Team
+	for i, input := range file.Teams {
+		team, err := checkTeam(input.apply(Team{ID: i + 1, Name: input.Name, ShortCode: input.ShortCode}), teams) // Temporary IDs so each team is checked against the ones before it
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("%w: team %d: %v", errInvalidImport, i+1, err)
+		}
+		teams = append(teams, team)
+	}
+	if len(teams) < 2 {
+		return nil, nil, 0, fmt.Errorf("%w: %v", errInvalidImport, errTooFewTeams)
+	}
+
+	fixtures := append([]importFixture{}, file.Fixtures...)
+	sort.SliceStable(fixtures, func(i, j int) bool { return fixtures[i].Week < fixtures[j].Week }) // Stored in week order
+	playedWeeks := 0                                                                               // Last week with results
+	busy := make(map[[2]int]bool)                                                                  // Team IDs already playing in a week
+	for i, fixture := range fixtures {
+		home, away := findTeam(teams, fixture.Home), findTeam(teams, fixture.Away)
+		switch {
+		case fixture.Week < 1:
+			return nil, nil, 0, fmt.Errorf("%w: fixture %d: invalid week %d", errInvalidImport, i+1, fixture.Week)
+		case home == nil || away == nil:
+			return nil, nil, 0, fmt.Errorf("%w: fixture %d: unknown team in %q v %q", errInvalidImport, i+1, fixture.Home, fixture.Away)
+		case home.ID == away.ID:
+			return nil, nil, 0, fmt.Errorf("%w: fixture %d: %s cannot play itself", errInvalidImport, i+1, home.Name)
+		case busy[[2]int{fixture.Week, home.ID}] || busy[[2]int{fixture.Week, away.ID}]:
+			return nil, nil, 0, fmt.Errorf("%w: fixture %d: a team plays twice in week %d", errInvalidImport, i+1, fixture.Week)
+		case (fixture.HomeScore == nil) != (fixture.AwayScore == nil):
+			return nil, nil, 0, fmt.Errorf("%w: fixture %d: both scores or neither are required", errInvalidImport, i+1)
+		case fixture.played() && (*fixture.HomeScore < 0 || *fixture.AwayScore < 0):
+			return nil, nil, 0, fmt.Errorf("%w: fixture %d: scores cannot be negative", errInvalidImport, i+1)
+		}
+		busy[[2]int{fixture.Week, home.ID}], busy[[2]int{fixture.Week, away.ID}] = true, true
+		if fixture.played() {
+			playedWeeks = fixture.Week
+		}
+	}
+
+	for i, fixture := range fixtures { // Results must cover whole weeks from week 1, since the season resumes after the last played week
+		if fixture.played() != (fixture.Week <= playedWeeks) {
+			return nil, nil, 0, fmt.Errorf("%w: fixture %d: every fixture up to week %d needs a result and no later fixture may have one", errInvalidImport, i+1, playedWeeks)
+		}
+	}
+	return teams, fixtures, playedWeeks, nil
+}
+
+func findTeam(teams []Team, nameOrCode string) *Team { // findTeam returns the team with the given name or short code, ignoring case
+	for i := range teams {
+		if strings.EqualFold(teams[i].Name, nameOrCode) || strings.EqualFold(teams[i].ShortCode, nameOrCode) {
+			return &teams[i]
+		}
+	}
+	return nil
+}
+
+func importLeague(store LeagueStore, file importFile, seed int64) (Season, error) { // importLeague archives the current season, replaces the league's teams and starts a new season with the imported fixtures and results, in one transaction
+	teams, fixtures, playedWeeks, err := checkImport(file)
+	if err != nil {
+		return Season{}, err
+	}
+
+	var season Season
+	err = store.Update(func(tx LeagueStore) error {
+		current, err := tx.Season()
+		if err == nil {
+			if err := tx.ArchiveSeason(current.ID); err != nil { // Keep the previous season's table with its old teams
+				return err
+			}
+		} else if !errors.Is(err, errNoSeason) {
+			return err
+		}
+
+		existing, err := tx.Teams()
+		if err != nil {
+			return err
+		}
+		for _, team := range existing { // The import replaces every team
+			if err := tx.RemoveTeam(team.ID); err != nil {
+				return err
+			}
+		}
+
+		ids := make(map[int]int) // Stored team ID by temporary import ID
+		for _, team := range teams {
+			added, err := tx.AddTeam(team)
+			if err != nil {
+				return err
+			}
+			ids[team.ID] = added.ID
+		}
+
+		if season, err = addSeason(tx, seed); err != nil {
+			return err
+		}
+		if len(fixtures) == 0 {
+			season, err = scheduleSeason(tx, season) // Fixtures are generated as for a new season
+			return err
+		}
+
+		var scheduled []Fixture
+		for _, fixture := range fixtures {
+			scheduled = append(scheduled, Fixture{HomeTeamID: ids[findTeam(teams, fixture.Home).ID], AwayTeamID: ids[findTeam(teams, fixture.Away).ID], Week: fixture.Week})
+		}
+		if err := tx.AddFixtures(season.ID, scheduled); err != nil {
+			return err
+		}
+		weeks := fixtures[len(fixtures)-1].Week
+		if err := tx.SetSchedule(season.ID, weeks, true); err != nil {
+			return err
+		}
+		if err := recordRatings(tx, season.ID, 0); err != nil { // Ratings at the start of the season
+			return err
+		}
+
+		matches, err := tx.Matches(season.ID, 0) // Stored in the same week order as fixtures
+		if err != nil {
+			return err
+		}
+		for i, fixture := range fixtures {
+			if !fixture.played() {
+				continue
+			}
+			match := matches[i]
+			match.HomeScore, match.AwayScore, match.Played = *fixture.HomeScore, *fixture.AwayScore, true
+			if err := saveMatch(tx, match); err != nil {
+				return err
+			}
+			if err := applyLeagueTable(tx, match, 1); err != nil {
+				return err
+			}
+		}
+		if err := replayRatings(tx, season.ID); err != nil { // Rate the imported results week by week
+			return err
+		}
+
+		for week := 1; week <= playedWeeks; week++ { // Resume the season after the last imported week
+			if err := tx.ClaimWeek(season, week); err != nil {
+				return err
+			}
+		}
+		if playedWeeks == weeks {
+			if err := tx.ArchiveSeason(season.ID); err != nil { // Every week was imported with results
+				return err
+			}
+		}
+
+		season, err = tx.Season()
+		return err
+	})
+	if err != nil {
+		return Season{}, err
+	}
+	return season, nil
+}
+
+func runImport(args []string) error { // runImport is the import command: it loads a JSON file, or a teams CSV and an optional fixtures CSV, into a league of the database
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	leagueCode := flags.String("league", defaultLeagueCode, "Code of the league to import into")
+	seed := flags.Int64("seed", 0, "Seed for fixtures generated when the file has none (0 for a random seed)")
+	dbPath := flags.String("db", defaultDatabasePath, "SQLite database file to import into")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: import [-db FILE] [-league CODE] [-seed N] league.json | teams.csv [fixtures.csv]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		flags.Usage()
+		return errors.New("import needs a JSON file, or a teams CSV and an optional fixtures CSV")
+	}
+
+	teams, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	var fixtures []byte
+	if flags.NArg() == 2 {
+		if fixtures, err = os.ReadFile(flags.Arg(1)); err != nil {
+			return err
+		}
+	}
+	file, err := decodeImport(teams, fixtures)
+	if err != nil {
+		return err
+	}
+
+	storage, err := openStore("sqlite", *dbPath) // Imports are only useful in a database file, which outlives the command
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	league, err := storage.FindLeague(*leagueCode)
+	if err != nil {
+		return fmt.Errorf("league %q: %w", *leagueCode, err)
+	}
+	if *seed == 0 {
+		*seed = newSeed()
+	}
+	season, err := importLeague(storage.League(league.ID), file, *seed)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Imported %d teams and %d fixtures into league %q (season %d, week %d of %d)", len(file.Teams), len(file.Fixtures), league.Code, season.ID, season.CurrentWeek, season.Weeks)
+	return nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import ( // Import required packages:
+	"errors"  // For matching error values
+	"strings" // For matching error messages
+	"testing" // For the test framework
+)
+
+func score(goals int) *int { // score returns a pointer to a fixture score
+	return &goals
+}
+
+func importTeams(names ...string) []importTeam { // importTeams returns teams of strength 2 with the given names
+	var teams []importTeam
+	for _, name := range names {
+		teams = append(teams, importTeam{Name: name, strengthsInput: strengthsInput{Strength: score(2)}})
+	}
+	return teams
+}
+
+func TestCheckImport(t *testing.T) { // An import needs two valid teams, and its fixtures known teams, one match a week per team and results for whole weeks from week 1
+	tests := []struct {
+		name        string
+		file        importFile
+		wantErr     string // Part of the error message, or empty for a valid import
+		playedWeeks int
+	}{
+		{
+			name: "teams only",
+			file: importFile{Teams: importTeams("Chelsea", "Arsenal")},
+		},
+		{
+			name: "results for the first week",
+			file: importFile{Teams: importTeams("Chelsea", "Arsenal", "Everton"), Fixtures: []importFixture{
+				{Week: 2, Home: "ARS", Away: "Chelsea"},
+				{Week: 1, Home: "chelsea", Away: "arsenal", HomeScore: score(2), AwayScore: score(1)},
+			}},
+			playedWeeks: 1,
+		},
+		{
+			name:    "too few teams",
+			file:    importFile{Teams: importTeams("Chelsea")},
+			wantErr: "at least 2 teams",
+		},
+		{
+			name:    "duplicate name",
+			file:    importFile{Teams: importTeams("Chelsea", "chelsea")},
+			wantErr: "team 2",
+		},
+		{
+			name:    "strength out of range",
+			file:    importFile{Teams: []importTeam{{Name: "Chelsea", strengthsInput: strengthsInput{Strength: score(9)}}, {Name: "Arsenal", strengthsInput: strengthsInput{Strength: score(1)}}}},
+			wantErr: "team 1",
+		},
+		{
+			name:    "unknown team",
+			file:    importFile{Teams: importTeams("Chelsea", "Arsenal"), Fixtures: []importFixture{{Week: 1, Home: "Chelsea", Away: "Spurs"}}},
+			wantErr: "unknown team",
+		},
+		{
+			name:    "team plays itself",
+			file:    importFile{Teams: importTeams("Chelsea", "Arsenal"), Fixtures: []importFixture{{Week: 1, Home: "Chelsea", Away: "CHE"}}},
+			wantErr: "cannot play itself",
+		},
+		{
+			name: "team plays twice in a week",
+			file: importFile{Teams: importTeams("Chelsea", "Arsenal", "Everton"), Fixtures: []importFixture{
+				{Week: 1, Home: "Chelsea", Away: "Arsenal"},
+				{Week: 1, Home: "Everton", Away: "Chelsea"},
+			}},
+			wantErr: "plays twice in week 1",
+		},
+		{
+			name:    "invalid week",
+			file:    importFile{Teams: importTeams("Chelsea", "Arsenal"), Fixtures: []importFixture{{Week: 0, Home: "Chelsea", Away: "Arsenal"}}},
+			wantErr: "invalid week",
+		},
+		{
+			name:    "one score only",
+			file:    importFile{Teams: importTeams("Chelsea", "Arsenal"), Fixtures: []importFixture{{Week: 1, Home: "Chelsea", Away: "Arsenal", HomeScore: score(1)}}},
+			wantErr: "both scores or neither",
+		},
+		{
+			name:    "negative score",
+			file:    importFile{Teams: importTeams("Chelsea", "Arsenal"), Fixtures: []importFixture{{Week: 1, Home: "Chelsea", Away: "Arsenal", HomeScore: score(-1), AwayScore: score(0)}}},
+			wantErr: "cannot be negative",
+		},
+		{
+			name: "results after an unplayed week",
+			file: importFile{Teams: importTeams("Chelsea", "Arsenal"), Fixtures: []importFixture{
+				{Week: 1, Home: "Chelsea", Away: "Arsenal"},
+				{Week: 2, Home: "Arsenal", Away: "Chelsea", HomeScore: score(0), AwayScore: score(0)},
+			}},
+			wantErr: "needs a result",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teams, fixtures, playedWeeks, err := checkImport(tt.file)
+			if tt.wantErr != "" {
+				if !errors.Is(err, errInvalidImport) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("checkImport() error = %v, want an invalid import mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkImport() error = %v", err)
+			}
+			if len(teams) != len(tt.file.Teams) || playedWeeks != tt.playedWeeks {
+				t.Errorf("checkImport() = %d teams and %d played weeks, want %d and %d", len(teams), playedWeeks, len(tt.file.Teams), tt.playedWeeks)
+			}
+			for i := 1; i < len(fixtures); i++ {
+				if fixtures[i].Week < fixtures[i-1].Week {
+					t.Errorf("fixtures not in week order: %+v", fixtures)
+				}
+			}
+		})
+	}
+}
+
+func TestImportLeague(t *testing.T) { // An import replaces the teams, archives the previous season and resumes after the last imported week
+	store := newMemoryStorage().League(defaultLeagueID)
+	if err := SeedDatabase(store, []string{"Chelsea", "Arsenal"}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	file := importFile{Teams: importTeams("Everton", "Fulham", "Brentford"), Fixtures: []importFixture{
+		{Week: 1, Home: "Everton", Away: "Fulham", HomeScore: score(3), AwayScore: score(1)},
+		{Week: 2, Home: "Fulham", Away: "Brentford", HomeScore: score(0), AwayScore: score(0)},
+		{Week: 3, Home: "Brentford", Away: "Everton"},
+	}}
+	season, err := importLeague(store, file, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if season.CurrentWeek != 2 || season.Weeks != 3 || season.Archived {
+		t.Errorf("season = %+v, want week 2 of 3", season)
+	}
+
+	seasons, err := store.Seasons()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seasons) != 2 || !seasons[1].Archived {
+		t.Errorf("seasons = %+v, want the seeded season archived", seasons)
+	}
+
+	table, err := leagueTable(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name           string
+		points, played int
+	}{{"Everton", 3, 1}, {"Brentford", 1, 1}, {"Fulham", 1, 2}}
+	if len(table) != len(want) {
+		t.Fatalf("table has %d teams, want %d", len(table), len(want))
+	}
+	for i, team := range table {
+		if team.Name != want[i].name || team.Points != want[i].points || team.Played != want[i].played {
+			t.Errorf("position %d = %s with %d points from %d matches, want %s with %d from %d", i+1, team.Name, team.Points, team.Played, want[i].name, want[i].points, want[i].played)
+		}
+	}
+}
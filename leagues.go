@@ -0,0 +1,115 @@
+package main
+
+import ( // Import required packages:
+	"crypto/rand"   // For unguessable league codes
+	"encoding/json" // For JSON decoding
+	"errors"        // For creating error values
+	"io"            // For detecting an empty request body
+	"math/big"      // For picking code characters uniformly
+	"net/http"      // For HTTP request handling
+	"strconv"       // For converting strings to integers
+	"strings"       // For trimming league names
+)
+
+var errLeagueNotFound = errors.New("league not found") // Returned by FindLeague for an unknown league code
+
+const (
+	defaultLeagueID   = 1                // ID of the league used by requests without a league parameter
+	defaultLeagueCode = "default"        // Code of the default league
+	defaultLeagueName = "Default league" // Name of the default league
+)
+
+const (
+	leagueCodeLength   = 8                                 // Number of characters in a league code
+	leagueCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789" // Characters used in league codes, without look-alikes such as l, 1, o and 0
+	maxLeagueNameLen   = 60                                // Longest league name accepted by POST /leagues
+)
+
+type League struct { // League is one independent league with its own teams, matches and seasons
+	ID   int    `json:"id"`   // League ID
+	Code string `json:"code"` // Shareable code that selects the league in every request
+	Name string `json:"name"` // League name
+}
+
+func newLeagueCode() (string, error) { // newLeagueCode returns a random league code that is hard to guess
+	code := make([]byte, leagueCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(leagueCodeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = leagueCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+func (srv *server) requestLeague(w http.ResponseWriter, r *http.Request) (League, LeagueStore, bool) { // requestLeague returns the league selected by the league parameter (the default league when it is missing) with its store, answering 404 for unknown codes
+	code := r.URL.Query().Get("league")
+	if code == "" {
+		code = defaultLeagueCode
+	}
+
+	league, err := srv.storage.FindLeague(code)
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch league") // 404 for unknown codes
+		return League{}, nil, false
+	}
+	return league, srv.storage.League(league.ID), true
+}
+
+func (srv *server) createLeagueHandler(w http.ResponseWriter, r *http.Request) { // createLeagueHandler creates a league with its teams and first season, and returns its shareable code
+	if r.Method != http.MethodPost {
+		writeError(w, r, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed") // Only POST creates a league
+		return
+	}
+
+	var body struct { // Optional JSON body; the teams default to the -teams flag
+		Name  string   `json:"name"`
+		Teams []string `json:"teams"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, r, http.StatusBadRequest, "invalid_input", "Invalid input") // Return error for invalid body
+		return
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+	if len(body.Name) > maxLeagueNameLen {
+		writeError(w, r, http.StatusBadRequest, "invalid_input", "League name is too long") // Return error for overlong names
+		return
+	}
+
+	names := teamNames
+	if body.Teams != nil {
+		var err error
+		names, err = checkTeamNames(body.Teams)
+		if err != nil {
+			storeError(w, r, err, "Invalid teams") // Same codes as /api/teams: 400 for invalid names, 409 for duplicates or too few teams
+			return
+		}
+	}
+
+	seed, err := parseSeasonSeed(r) // Seed for the new league's strengths and fixtures, overridable with the seed parameter
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_seed", "Invalid seed parameter") // Return error for invalid seed
+		return
+	}
+
+	code, err := newLeagueCode()
+	if err != nil {
+		storeError(w, r, err, "Failed to create league")
+		return
+	}
+	league, err := srv.storage.CreateLeague(code, body.Name, func(tx LeagueStore) error { // The league is stored with its teams and first season, or not at all
+		return SeedDatabase(tx, names, seed)
+	})
+	if err != nil {
+		storeError(w, r, err, "Failed to create league") // Return error if the league cannot be stored or seeded
+		return
+	}
+
+	w.Header().Set("X-Simulation-Seed", strconv.FormatInt(seed, 10)) // Report the seed used
+	w.Header().Set("Location", "/?league="+league.Code)              // Page of the new league
+	w.Header().Set("Content-Type", "application/json")               // Set before the status, which sends the headers
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, league)
+}
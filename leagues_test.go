@@ -0,0 +1,69 @@
+package main
+
+import ( // Import required packages:
+	"encoding/json"     // For decoding the created league
+	"net/http"          // For HTTP status codes
+	"net/http/httptest" // For recording responses
+	"strings"           // For request bodies and checking HTML output
+	"testing"           // For the test framework
+)
+
+func TestCreateLeagueHandler(t *testing.T) { // A new league gets its own code and teams, and its team names are escaped in the HTML pages
+	srv := newTestServer(t, "Chelsea", "Arsenal")
+	create := jsonHandler(srv.createLeagueHandler)
+
+	tests := []struct {
+		name       string
+		method     string
+		query      string
+		body       string
+		wantStatus int
+	}{
+		{"GET", http.MethodGet, "", "", http.StatusMethodNotAllowed},
+		{"invalid body", http.MethodPost, "", "{", http.StatusBadRequest},
+		{"duplicate teams", http.MethodPost, "", `{"teams":["Everton","everton"]}`, http.StatusConflict},
+		{"one team", http.MethodPost, "", `{"teams":["Everton"," "]}`, http.StatusConflict},
+		{"overlong team name", http.MethodPost, "", `{"teams":["Everton","` + strings.Repeat("x", maxTeamNameLen+1) + `"]}`, http.StatusBadRequest},
+		{"invalid seed", http.MethodPost, "?seed=abc", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		create(w, httptest.NewRequest(tt.method, "/leagues"+tt.query, strings.NewReader(tt.body)))
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.wantStatus)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	create(w, httptest.NewRequest(http.MethodPost, "/leagues?seed=1", strings.NewReader(`{"name":"Cup","teams":["<b>Rovers</b>","Town"]}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status %d, want %d: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var league League
+	if err := json.NewDecoder(w.Body).Decode(&league); err != nil {
+		t.Fatal(err)
+	}
+	if league.ID == defaultLeagueID || len(league.Code) != leagueCodeLength || league.Name != "Cup" || w.Header().Get("Location") != "/?league="+league.Code {
+		t.Errorf("created league %+v at %q", league, w.Header().Get("Location"))
+	}
+
+	teams, err := srv.storage.League(league.ID).Teams()
+	if err != nil || len(teams) != 2 || teams[0].Name != "<b>Rovers</b>" {
+		t.Errorf("new league teams = %+v, %v, want its own two teams", teams, err)
+	}
+	if teams, err := srv.storage.League(defaultLeagueID).Teams(); err != nil || len(teams) != 2 || teams[0].Name != "Chelsea" {
+		t.Errorf("default league teams = %+v, %v, want them unchanged", teams, err)
+	}
+
+	w = httptest.NewRecorder()
+	htmlHandler(srv.allLeagueHandler)(w, httptest.NewRequest(http.MethodGet, "/all?seed=1&league="+league.Code, nil))
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "<b>Rovers</b>") || !strings.Contains(w.Body.String(), "&lt;b&gt;Rovers&lt;/b&gt;") {
+		t.Errorf("season of the new league = %d, want team names escaped:\n%s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	htmlHandler(srv.simulateHandler)(w, httptest.NewRequest(http.MethodGet, "/simulate?league=nosuchcode", nil))
+	if w.Code != http.StatusNotFound || w.Header().Get("X-Error-Code") != "league_not_found" {
+		t.Errorf("unknown league answered %d %q, want 404 league_not_found", w.Code, w.Header().Get("X-Error-Code"))
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import ( // Import required packages:
+	"errors"   // For creating error values
+	"net/http" // For HTTP request handling
+	"sync"     // For the per-league mutexes
+)
+
+var errLeagueBusy = errors.New("the league is being changed by another request, try again") // Returned when a league's lock is already held
+
+type leagueLocks struct { // leagueLocks serializes simulations and other changes per league, so two requests never change one league at once
+	mu    sync.Mutex          // Guards locks
+	locks map[int]*sync.Mutex // Lock of each league, created on first use
+}
+
+func (l *leagueLocks) tryLock(leagueID int) (func(), bool) { // tryLock takes a league's lock without waiting, returning its unlock function, or false if another request holds it
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[int]*sync.Mutex)
+	}
+	lock, ok := l.locks[leagueID]
+	if !ok {
+		lock = &sync.Mutex{}
+		l.locks[leagueID] = lock
+	}
+	l.mu.Unlock()
+
+	if !lock.TryLock() {
+		return nil, false
+	}
+	return lock.Unlock, true
+}
+
+func (srv *server) lockLeague(w http.ResponseWriter, r *http.Request, leagueID int) (func(), bool) { // lockLeague takes a league's lock for a changing request, answering 409 Conflict if another request is changing the league
+	unlock, ok := srv.locks.tryLock(leagueID)
+	if !ok {
+		storeError(w, r, errLeagueBusy, "")
+		return nil, false
+	}
+	return unlock, true
+}
@@ -0,0 +1,53 @@
+package main
+
+import ( // Import required packages:
+	"net/http"          // For HTTP status codes
+	"net/http/httptest" // For recording responses
+	"testing"           // For the test framework
+)
+
+func TestLeagueLocks(t *testing.T) { // A league's lock is held by one request at a time, and leagues do not block each other
+	var locks leagueLocks
+	unlock, ok := locks.tryLock(1)
+	if !ok {
+		t.Fatal("first lock of league 1 failed")
+	}
+	if _, ok := locks.tryLock(1); ok {
+		t.Error("league 1 locked twice")
+	}
+	unlockOther, ok := locks.tryLock(2)
+	if !ok {
+		t.Error("league 2 blocked by league 1")
+	} else {
+		unlockOther()
+	}
+	unlock()
+	if unlock, ok := locks.tryLock(1); !ok {
+		t.Error("league 1 still locked after unlock")
+	} else {
+		unlock()
+	}
+}
+
+func TestLeagueBusy(t *testing.T) { // A week requested while another request is changing the league is answered with 409 league_busy and not played
+	srv := newTestServer(t, "Chelsea", "Arsenal")
+	store := srv.storage.League(defaultLeagueID)
+	simulate := htmlHandler(srv.simulateHandler)
+
+	unlock, _ := srv.locks.tryLock(defaultLeagueID) // Another request is changing the league
+	w := httptest.NewRecorder()
+	simulate(w, httptest.NewRequest(http.MethodGet, "/simulate?seed=1", nil))
+	if w.Code != http.StatusConflict || w.Header().Get("X-Error-Code") != "league_busy" {
+		t.Errorf("busy league answered %d %q, want 409 league_busy", w.Code, w.Header().Get("X-Error-Code"))
+	}
+	if season := currentSeason(t, store); season.CurrentWeek != 0 {
+		t.Errorf("busy league played week %d", season.CurrentWeek)
+	}
+
+	unlock()
+	w = httptest.NewRecorder()
+	simulate(w, httptest.NewRequest(http.MethodGet, "/simulate?seed=1", nil))
+	if w.Code != http.StatusOK || currentSeason(t, store).CurrentWeek != 1 {
+		t.Errorf("free league answered %d, want week 1 played", w.Code)
+	}
+}
@@ -3,59 +3,112 @@ package main
 import ( // Import required packages:
 	"database/sql"  // For database operations
 	"encoding/json" // For JSON encoding and decoding
+	"errors"        // For creating error values
+	"flag"          // For parsing command-line flags
 	"fmt"           // For formatted I/O
+	"html"          // For escaping user-supplied names in HTML output
 	"log"           // For logging errors
 	"math/rand"     // For generating random numbers
 	"net/http"      // For HTTP server and request handling
+	"os"            // For the command-line arguments
 	"sort"          // For sorting slices
 	"strconv"       // For converting strings to integers
-	"time"          // For time-related functions
+	"strings"       // For splitting and trimming strings
 
 	_ "modernc.org/sqlite" // SQLite driver (without CGO)
 )
 
+var teamNames []string // Names of the teams seeded into the league, set from the -teams flag
+
+var leagueSeed int64 // Seed for team strengths and fixtures, set from the -seed flag (0 picks a new seed for every season)
+
+var ( // Errors returned when a match result cannot be edited
+	errMatchNotFound  = errors.New("match not found")
+	errMatchNotPlayed = errors.New("match has not been played yet")
+)
+
+var errWeekAlreadyPlayed = errors.New("week has already been played") // Returned when a week was claimed by another request
+
 type Team struct { // Team represents a football team with its attributes
-	ID       int    // Team ID
-	Name     string // Team name
-	Points   int    // Points earned
-	Played   int    // Matches played
-	Won      int    // Matches won
-	Drawn    int    // Matches drawn
-	Lost     int    // Matches lost
-	GF       int    // Goals for
-	GA       int    // Goals against
-	GD       int    // Goal difference
-	Strength int    // Team strength
+	ID            int     `json:"id"`            // Team ID
+	Name          string  `json:"name"`          // Team name
+	ShortCode     string  `json:"shortCode"`     // Short code of the team, e.g. CHE
+	Points        int     `json:"points"`        // Points earned
+	Played        int     `json:"played"`        // Matches played
+	Won           int     `json:"won"`           // Matches won
+	Drawn         int     `json:"drawn"`         // Matches drawn
+	Lost          int     `json:"lost"`          // Matches lost
+	GF            int     `json:"gf"`            // Goals for
+	GA            int     `json:"ga"`            // Goals against
+	GD            int     `json:"gd"`            // Goal difference
+	Strength      int     `json:"strength"`      // Team strength
+	Attack        int     `json:"attack"`        // Attacking strength, which raises the goals the team scores
+	Defense       int     `json:"defense"`       // Defensive strength, which lowers the goals the team concedes
+	HomeAdvantage int     `json:"homeAdvantage"` // Extra goals the team is expected to score at home, in percent
+	Elo           float64 `json:"elo"`           // Elo rating, updated after every match
 }
 
 type Match struct { // Match represents a football match played between two teams
-	ID         int // Match ID
-	HomeTeamID int // Home team ID
-	AwayTeamID int // Away team ID
-	HomeScore  int // Home team score
-	AwayScore  int // Away team score
-	Week       int // Week of match
+	ID         int    `json:"id"`         // Match ID
+	HomeTeamID int    `json:"homeTeamId"` // Home team ID
+	AwayTeamID int    `json:"awayTeamId"` // Away team ID
+	HomeTeam   string `json:"homeTeam"`   // Home team name
+	AwayTeam   string `json:"awayTeam"`   // Away team name
+	HomeScore  int    `json:"homeScore"`  // Home team score
+	AwayScore  int    `json:"awayScore"`  // Away team score
+	Week       int    `json:"week"`       // Week of match
+	Played     bool   `json:"played"`     // Whether match has been played
+}
+
+type Season struct { // Season represents the state of the league season held by the server
+	ID          int   `json:"id"`          // Season ID
+	CurrentWeek int   `json:"currentWeek"` // Last week played (0 before the first week)
+	Weeks       int   `json:"weeks"`       // Number of weeks in the season
+	Finished    bool  `json:"finished"`    // Whether every week has been played
+	Archived    bool  `json:"archived"`    // Whether the season's final standings have been archived
+	Scheduled   bool  `json:"scheduled"`   // Whether the season's fixtures have been generated
+	FixtureSeed int64 `json:"-"`           // Seed the fixtures are generated from, again whenever the teams change before the first week
 }
 
 type TeamPrediction struct { // TeamPrediction represents the predicted probability of a team winning the championship
-	Name        string  // Team name
-	Probability float64 // Probability of winning
+	Name           string    `json:"name"`           // Team name
+	Probability    float64   `json:"probability"`    // Probability of winning, in percent
+	Positions      []float64 `json:"positions"`      // Probability of finishing in each position (index 0 is 1st), in percent
+	ExpectedPoints float64   `json:"expectedPoints"` // Expected points at the end of the season
 }
 
 func main() { // HTTP handlers for different routes on Front-end
-	http.HandleFunc("/", indexHandler)
-	http.HandleFunc("/simulate", simulateHandler)
-	http.HandleFunc("/all", allLeagueHandler)
-	http.HandleFunc("/changeStrengths", changeStrengthsHandler)
-	http.HandleFunc("/teamStrengths", getTeamStrengthsHandler)
+	if len(os.Args) > 1 && os.Args[1] == "import" { // import command: load teams and fixtures into the database instead of serving
+		if err := runImport(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	teamsFlag := flag.String("teams", "Chelsea,Arsenal,Manchester City,Liverpool", "Comma-separated list of team names in the league")
+	flag.Int64Var(&leagueSeed, "seed", 0, "Seed for team strengths and fixtures (0 for a random seed)")
+	storeFlag := flag.String("store", "sqlite", "Where the league is stored: sqlite (the -db file) or memory (lost on exit)")
+	dbFlag := flag.String("db", defaultDatabasePath, "SQLite database file used by -store sqlite")
+	flag.Parse()
+
+	names, err := parseTeamNames(*teamsFlag) // Validate the team names given on the command line
+	if err != nil {
+		log.Fatal(err) // Exit if the team list is unusable
+	}
+	teamNames = names
 
-	db, err := SetupDatabase() // Initialize the database
+	storage, err := openStore(*storeFlag, *dbFlag) // One storage shared by every request
 	if err != nil {
-		panic(err) // Return error if database fails to initialize
+		log.Fatal(err) // Exit if the store is unknown or cannot be opened
+	}
+	defer storage.Close()
+
+	if err := SeedDatabase(storage.League(defaultLeagueID), teamNames, seasonSeed()); err != nil { // Seed an empty default league with initial team data, keeping any existing league
+		log.Fatal(err) // Exit if the league cannot be seeded
 	}
-	defer db.Close()
 
-	SeedDatabase(db) // Seed the database with initial team data
+	srv := &server{storage: storage}
+	srv.routes() // HTTP handlers for different routes on Front-end
 
 	fmt.Println("Server active at http://localhost:8080/")
 	log.Fatal(http.ListenAndServe(":8080", nil)) // Start the HTTP server
@@ -65,180 +118,324 @@ func indexHandler(w http.ResponseWriter, r *http.Request) { // indexHandler serv
 	http.ServeFile(w, r, "index.html")
 }
 
-func simulateHandler(w http.ResponseWriter, r *http.Request) { // simulateHandler handles the simulation of 2 matches in a week
-	weekStr := r.URL.Query().Get("week") // Retrieve relevant week from URL from Front-end query
-	week, err := strconv.Atoi(weekStr)   // Convert week from string to int
+func (srv *server) simulateHandler(w http.ResponseWriter, r *http.Request) { // simulateHandler plays the next week of the season held by the server
+	seed, err := parseSeed(r) // Optional seed so the week can be replayed
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_seed", "Invalid seed parameter") // Return error for invalid seed
+		return
+	}
+
+	league, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	unlock, ok := srv.lockLeague(w, r, league.ID) // Only one request may change the league at a time
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	season, err := store.Season() // Current week is held by the server, not the browser
 	if err != nil {
-		http.Error(w, "Invalid week parameter", http.StatusBadRequest) // Return error for invalid week
+		storeError(w, r, err, "Failed to fetch season") // Return error if query fails
 		return
 	}
 
-	db, err := sql.Open("sqlite", "file:league.db?cache=shared&mode=rwc&_loc=auto") // Open database via SQL
+	week, ok := checkNextWeek(w, r, season) // Only the week after the last played one can be simulated
+	if !ok {
+		return
+	}
+
+	output := fmt.Sprintf("<p>Simulation seed: %d</p>\n", seed) // Generate HTML output for the results to display on Front-end
+	err = store.Update(func(tx LeagueStore) error {             // Play the week in one transaction, so a failure leaves the week unplayed
+		weekOutput, err := simulateWeek(tx, season, week, weekRand(seed, week))
+		output += weekOutput
+		return err
+	})
 	if err != nil {
-		http.Error(w, "Database error", http.StatusInternalServerError) // Return error if database fails to open
+		storeError(w, r, err, "Failed to simulate week") // 409 if another request played the week first; the week's changes have been rolled back
 		return
 	}
-	defer db.Close() // Ensure database is closed by end of function
 
-	PlayWeekMatches(db, week) // Simulate matches for the specified week
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("X-Simulation-Seed", strconv.FormatInt(seed, 10)) // Report the seed used
+	fmt.Fprint(w, output)
+}
+
+func (srv *server) allLeagueHandler(w http.ResponseWriter, r *http.Request) { // allLeagueHandler handles the simulation of all weeks from the next week to the last week
+	seed, err := parseSeed(r) // Optional seed so the remaining season can be replayed
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid_seed", "Invalid seed parameter") // Return error for invalid seed
+		return
+	}
+
+	league, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	unlock, ok := srv.lockLeague(w, r, league.ID) // Only one request may change the league at a time
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	season, err := store.Season() // Current week is held by the server, not the browser
+	if err != nil {
+		storeError(w, r, err, "Failed to fetch season") // Return error if query fails
+		return
+	}
+
+	startWeek, ok := checkNextWeek(w, r, season) // Play on from the week after the last played one
+	if !ok {
+		return
+	}
+
+	output := fmt.Sprintf("<p>Simulation seed: %d</p>\n", seed) // Generate HTML output for the results to display on Front-end for remaining weeks
+	err = store.Update(func(tx LeagueStore) error {             // Play every remaining week in one transaction, so a failure leaves the season where it was
+		for week := startWeek; week <= season.Weeks; week++ {
+			weekOutput, err := simulateWeek(tx, season, week, weekRand(seed, week)) // Same random source as simulating this week alone with the same seed
+			if err != nil {
+				return err
+			}
+			season.CurrentWeek = week
+			output += weekOutput + "<hr>\n"
+		}
+		return nil
+	})
+	if err != nil {
+		storeError(w, r, err, "Failed to simulate week") // 409 if another request played the week first; the week's changes have been rolled back
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("X-Simulation-Seed", strconv.FormatInt(seed, 10)) // Report the seed used
+	fmt.Fprint(w, output)
+}
+
+func simulateWeek(store LeagueStore, season Season, week int, rng *rand.Rand) (string, error) { // simulateWeek claims and plays one week, archiving the season after its last week, and returns the week's HTML results
+	if err := store.ClaimWeek(season, week); err != nil { // Mark the week as played so it cannot be played twice
+		return "", err
+	}
+
+	if err := PlayWeekMatches(store, season.ID, week, rng); err != nil { // Simulate matches for the specified week
+		return "", err
+	}
+
+	table, err := displayTableHTML(store)
+	if err != nil {
+		return "", err
+	}
+	results, err := displayMatchResultsHTML(store, season.ID, week)
+	if err != nil {
+		return "", err
+	}
 
-	// Generate HTML output for the results to display on Front-end
 	output := fmt.Sprintf("<h2>%d%s Week</h2>\n", week, getOrdinalSuffix(week))
 	output += "<h3>League Table</h3>\n"
 	output += "<pre>\n"
-	output += displayTableHTML(db)
+	output += table
 	output += "</pre>\n"
 	output += "<h3>Match Results</h3>\n"
 	output += "<pre>\n"
-	output += displayMatchResultsHTML(db, week)
+	output += results
 	output += "</pre>\n"
 
-	if week >= 4 { // Display predictions after week 4
+	if week >= predictionsFromWeek(season.Weeks) { // Display predictions in the final third of the season
+		predictions, err := displayPredictionsHTML(store, season.ID, week, rng)
+		if err != nil {
+			return "", err
+		}
 		output += "<h3>Predictions for Championship</h3>\n"
 		output += "<pre>\n"
-		output += displayPredictionsHTML(db, week)
+		output += predictions
 		output += "</pre>\n"
 	}
 
-	w.Header().Set("Content-Type", "text/html")
-	fmt.Fprintf(w, output)
+	if week >= season.Weeks { // Archive the final standings after the last week
+		if err := store.ArchiveSeason(season.ID); err != nil {
+			return "", err
+		}
+	}
+
+	return output, nil
+}
 
-	if week >= 5 { // Reset the database after week 5 for new simulation
-		db, err = SetupDatabase() // Initialize the database
+func checkNextWeek(w http.ResponseWriter, r *http.Request, season Season) (int, bool) { // checkNextWeek returns the next week to play, rejecting finished seasons and replayed or skipped weeks
+	if season.Finished {
+		writeError(w, r, http.StatusConflict, "season_finished", "The season has finished") // Return error if every week has been played
+		return 0, false
+	}
+
+	week := season.CurrentWeek + 1                           // The only week that can be played next
+	if weekStr := r.URL.Query().Get("week"); weekStr != "" { // Optional week from Front-end, checked against the server's state
+		requested, err := strconv.Atoi(weekStr) // Convert week from string to int
 		if err != nil {
-			http.Error(w, "Failed to reset database", http.StatusInternalServerError) // Return error if database fails to reset
-			return
+			writeError(w, r, http.StatusBadRequest, "invalid_week", "Invalid week parameter") // Return error for invalid week
+			return 0, false
+		}
+		if requested != week {
+			writeError(w, r, http.StatusConflict, "wrong_week", fmt.Sprintf("Week %d cannot be played, the next week is week %d", requested, week)) // Return error for replayed or skipped weeks
+			return 0, false
 		}
-		defer db.Close() // Ensure database is closed by end of function
-
-		SeedDatabase(db) // Seed the database with initial team data
 	}
+
+	return week, true
+}
+
+type teamStrengths struct { // teamStrengths are the match engine parameters of a team sent by /teamStrengths
+	Strength      int `json:"strength"`      // Team strength
+	Attack        int `json:"attack"`        // Attacking strength
+	Defense       int `json:"defense"`       // Defensive strength
+	HomeAdvantage int `json:"homeAdvantage"` // Extra goals expected at home, in percent
 }
 
-func allLeagueHandler(w http.ResponseWriter, r *http.Request) { // allLeagueHandler handles the simulation of all weeks from current week to week 5
-	db, err := sql.Open("sqlite", "file:league.db?cache=shared&mode=rwc&_loc=auto") // Open database via SQL
+func (srv *server) changeStrengthsHandler(w http.ResponseWriter, r *http.Request) { // changeStrengthsHandler handles update of team strengths, attacks, defenses and home advantages
+	var body map[string]json.RawMessage
+	err := json.NewDecoder(r.Body).Decode(&body) // Parse the JSON body to get new team strengths
 	if err != nil {
-		http.Error(w, "Database error", http.StatusInternalServerError) // Return error if database fails to open
+		writeError(w, r, http.StatusBadRequest, "invalid_input", "Invalid input") // Return error for invalid body
 		return
 	}
-	defer db.Close()
 
-	weekStr := r.URL.Query().Get("week")    // Retrieve relevant week from URL from Front-end query
-	startWeek, err := strconv.Atoi(weekStr) // Convert week from string to int
-	if err != nil || startWeek < 1 || startWeek > 5 {
-		startWeek = 1 // Default to week 1 if week parameter is missing, invalid, or out of range
+	strengths := make(map[string]strengthsInput, len(body)) // Map of team name to its new parameters
+	for team, raw := range body {
+		var input strengthsInput
+		var strength int
+		if json.Unmarshal(raw, &strength) == nil {
+			input.Strength = &strength // A bare number is the team strength, as before attack and defense existed
+		} else if err := json.Unmarshal(raw, &input); err != nil {
+			writeError(w, r, http.StatusBadRequest, "invalid_input", "Invalid input") // Return error for invalid strength
+			return
+		}
+		strengths[team] = input
 	}
 
-	output := "" // Generate HTML output for the results to display on Front-end for remaining weeks
-	for week := startWeek; week <= 5; week++ {
-		PlayWeekMatches(db, week) // Simulate matches for the specified week
-
-		output += fmt.Sprintf("<h2>%d%s Week</h2>\n", week, getOrdinalSuffix(week))
-		output += "<h3>League Table</h3>\n"
-		output += "<pre>\n"
-		output += displayTableHTML(db)
-		output += "</pre>\n"
-		output += "<h3>Match Results</h3>\n"
-		output += "<pre>\n"
-		output += displayMatchResultsHTML(db, week)
-		output += "</pre>\n"
-
-		if week >= 4 { // Display predictions after week 4
-			output += "<h3>Predictions for Championship</h3>\n"
-			output += "<pre>\n"
-			output += displayPredictionsHTML(db, week)
-			output += "</pre>\n"
-		}
+	league, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
 
-		output += "<hr>\n"
+	unlock, ok := srv.lockLeague(w, r, league.ID) // Only one request may change the league at a time
+	if !ok {
+		return
 	}
+	defer unlock()
 
-	w.Header().Set("Content-Type", "text/html")
-	fmt.Fprintf(w, output)
+	err = store.Update(func(tx LeagueStore) error { // Update every team or none
+		season, err := tx.Season()
+		if err != nil && !errors.Is(err, errNoSeason) {
+			return err
+		}
+		started := err == nil && season.CurrentWeek > 0 // Ratings earned this season are replayed from week 0 when results are edited, so a reset would not last
 
-	// Reset the database after simulating all weeks
-	db, err = SetupDatabase() // Initialize the database
+		teams, err := tx.Teams()
+		if err != nil {
+			return err
+		}
+		for _, team := range teams { // Update the strength of each team
+			input, ok := strengths[team.Name]
+			if !ok {
+				continue // Unknown names are skipped
+			}
+			rating := team.Elo
+			team = input.apply(team)
+			if checkStrengths(team) != nil {
+				continue // Skip invalid strength values
+			}
+			if started {
+				team.Elo = rating // Only a new strength before the first week resets the rating
+			}
+			if err := tx.EditTeam(team); err != nil {
+				return err
+			}
+		}
+		if season.ID == 0 || started {
+			return nil
+		}
+		return recordRatings(tx, season.ID, 0) // Reset ratings are the season's starting ratings
+	})
 	if err != nil {
-		http.Error(w, "Failed to reset database", http.StatusInternalServerError) // Return error if database fails to reset
+		storeError(w, r, err, "Failed to update team strength") // Return error if fail to update
 		return
 	}
-	defer db.Close() // Ensure database is closed by end of function
 
-	SeedDatabase(db) // Seed the database with initial team data
+	writeJSON(w, map[string]bool{"success": true}) // Respond with success
 }
 
-func changeStrengthsHandler(w http.ResponseWriter, r *http.Request) { // changeStrengthsHandler handles update of team strengths
-	var strengths map[string]int
-	err := json.NewDecoder(r.Body).Decode(&strengths) // Parse the JSON body to get new team strengths
-	if err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest) // Return error for invalid strength
+func (srv *server) getTeamStrengthsHandler(w http.ResponseWriter, r *http.Request) { // getTeamStrengthsHandler sends current team strengths, attacks, defenses and home advantages to Front-end
+	_, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
 		return
 	}
 
-	db, err := sql.Open("sqlite", "file:league.db?cache=shared&mode=rwc&_loc=auto") // Open database via SQL
+	teams, err := store.Teams() // Retrieve team strengths
 	if err != nil {
-		http.Error(w, "Database error", http.StatusInternalServerError) // Return error if database fails to open
+		storeError(w, r, err, "Failed to fetch team strengths") // Return error if query fails
 		return
 	}
-	defer db.Close() // Ensure database is closed by end of function
 
-	stmt, err := db.Prepare("UPDATE teams SET strength = ? WHERE name = ?") // Prepare an update statement for changing team strengths
-	if err != nil {
-		http.Error(w, "Failed to prepare statement", http.StatusInternalServerError) // Return error if statement fails
-		return
+	strengths := make(map[string]teamStrengths) // Initialize map to hold team strengths
+	for _, team := range teams {
+		strengths[team.Name] = teamStrengths{Strength: team.Strength, Attack: team.Attack, Defense: team.Defense, HomeAdvantage: team.HomeAdvantage} // Add team strengths to map
 	}
-	defer stmt.Close() // Ensure statement is closed by end of function
 
-	for team, strength := range strengths { // Execute the update statement for each team
-		if strength < 1 || strength > 4 {
-			continue // Skip invalid strength values
+	writeJSON(w, strengths)
+}
+
+func parseTeamNames(list string) ([]string, error) { // parseTeamNames splits a comma-separated team list and validates it
+	return checkTeamNames(strings.Split(list, ","))
+}
+
+func checkTeamNames(list []string) ([]string, error) { // checkTeamNames trims team names, dropping empty ones, and rejects them by the same rules as /api/teams and leagues of fewer than 2 teams
+	var teams []Team // Teams checked so far, which later names must differ from
+	for _, name := range list {
+		if strings.TrimSpace(name) == "" {
+			continue // Skip empty entries such as trailing commas
 		}
-		_, err = stmt.Exec(strength, team)
+		team, err := checkTeam(Team{ID: len(teams) + 1, Name: name, Strength: minStrength, Attack: minStrength, Defense: minStrength}, teams) // Temporary ID and strengths, as only the name is checked
 		if err != nil {
-			http.Error(w, "Failed to update team strength", http.StatusInternalServerError) // Return error if fail to update
-			return
+			return nil, err // errInvalidTeam for overlong names, errDuplicateTeam for names differing only in case
 		}
+		teams = append(teams, team)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]bool{"success": true}) // Respond with success
-}
-
-func getTeamStrengthsHandler(w http.ResponseWriter, r *http.Request) { // getTeamStrengthsHandler sends current team strengths to Front-end
-	db, err := sql.Open("sqlite", "file:league.db?cache=shared&mode=rwc&_loc=auto") // Open database via SQL
-	if err != nil {
-		http.Error(w, "Database error", http.StatusInternalServerError) // Return error if database fails to open
-		return
+	if len(teams) < 2 {
+		return nil, errTooFewTeams
 	}
-	defer db.Close() // Ensure database is closed by end of function
-
-	rows, err := db.Query("SELECT name, strength FROM teams") // Query to retrieve team strengths
-	if err != nil {
-		http.Error(w, "Failed to fetch team strengths", http.StatusInternalServerError) // Return error if query fails
-		return
+	names := make([]string, len(teams))
+	for i, team := range teams {
+		names[i] = team.Name
 	}
-	defer rows.Close() // Ensure rows are closed by end of function
+	return names, nil
+}
 
-	strengths := make(map[string]int) // Initialize map to hold team strengths
-	for rows.Next() {
-		var name string
-		var strength int
-		if err := rows.Scan(&name, &strength); err != nil {
-			http.Error(w, "Failed to fetch team strengths", http.StatusInternalServerError) // Return error if row scan fails
-			return
-		}
-		strengths[name] = strength // Add team strength to map
+func parseSeed(r *http.Request) (int64, error) { // parseSeed reads the optional seed query parameter, picking a new seed when it is missing
+	seedStr := r.URL.Query().Get("seed")
+	if seedStr == "" {
+		return newSeed(), nil
 	}
+	return strconv.ParseInt(seedStr, 10, 64)
+}
 
-	if err := rows.Err(); err != nil {
-		http.Error(w, "Failed to fetch team strengths", http.StatusInternalServerError) // Return error if row processing fails
-		return
+func parseSeasonSeed(r *http.Request) (int64, error) { // parseSeasonSeed reads the optional seed query parameter of a request starting a season, using seasonSeed when it is missing
+	seedStr := r.URL.Query().Get("seed")
+	if seedStr == "" {
+		return seasonSeed(), nil
 	}
+	return strconv.ParseInt(seedStr, 10, 64)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(strengths); err != nil {
-		http.Error(w, "Failed to encode team strengths", http.StatusInternalServerError) // Return error if JSON encoding fails
+func seasonSeed() int64 { // seasonSeed returns the seed for a new season's strengths and fixtures
+	if leagueSeed != 0 {
+		return leagueSeed // Same seed every season so seasons can be reproduced
 	}
+	return newSeed()
+}
+
+func predictionsFromWeek(seasonWeeks int) int { // predictionsFromWeek returns the first week predictions are shown, the start of the final third
+	return seasonWeeks * 2 / 3
 }
 
 func getOrdinalSuffix(n int) string { // getOrdinalSuffix returns the ordinal suffix (st, nd, rd, th) for each week number
@@ -263,384 +460,345 @@ func getOrdinalSuffix(n int) string { // getOrdinalSuffix returns the ordinal su
 	}
 }
 
-func SetupDatabase() (*sql.DB, error) { // SetupDatabase sets up the SQLite database with necessary tables
-	db, err := sql.Open("sqlite", "file:league.db?cache=shared&mode=rwc&_loc=auto") // Open database via SQL
-	if err != nil {
-		return nil, err
-	}
-
-	dropTeamsTable := `DROP TABLE IF EXISTS teams;` // SQL statements to drop existing tables if any
-	dropMatchesTable := `DROP TABLE IF EXISTS matches;`
-
-	_, err = db.Exec(dropTeamsTable) // Execute DROP TABLE statement for teams
+func SetupDatabase(path string) (*sql.DB, error) { // SetupDatabase opens the database file at path and applies any pending schema migrations, keeping existing league data
+	db, err := sql.Open("sqlite", databaseDSN(path)) // Open database via SQL
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = db.Exec(dropMatchesTable) // Execute DROP TABLE statement for matches
-	if err != nil {
-		return nil, err
-	}
+	db.SetMaxOpenConns(maxOpenConns) // Limit the pool shared by every request
+	db.SetMaxIdleConns(maxOpenConns) // Keep connections open between requests instead of reopening the file
+	db.SetConnMaxIdleTime(connMaxIdleTime)
 
-	// SQL statements to create new tables for teams and matches
-	createTeamsTable := `CREATE TABLE IF NOT EXISTS teams (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        name TEXT,
-        points INTEGER DEFAULT 0,
-        played INTEGER DEFAULT 0,
-        won INTEGER DEFAULT 0,
-        drawn INTEGER DEFAULT 0,
-        lost INTEGER DEFAULT 0,
-        gf INTEGER DEFAULT 0,
-        ga INTEGER DEFAULT 0,
-        gd INTEGER DEFAULT 0,
-        strength INTEGER DEFAULT 1
-    );`
-
-	createMatchesTable := `CREATE TABLE IF NOT EXISTS matches (
-        id INTEGER PRIMARY KEY AUTOINCREMENT,
-        home_team_id INTEGER,
-        away_team_id INTEGER,
-        home_score INTEGER,
-        away_score INTEGER,
-        week INTEGER
-    );`
-
-	_, err = db.Exec(createTeamsTable) // Execute CREATE TABLE statement for teams
-	if err != nil {
-		return nil, err
-	}
-
-	_, err = db.Exec(createMatchesTable) // Execute CREATE TABLE statement for matches
-	if err != nil {
+	if err := migrateDatabase(db); err != nil { // Bring the schema up to date (see migrations.go)
+		db.Close()
 		return nil, err
 	}
 
 	return db, nil // Return initialized database
 }
 
-func SeedDatabase(db *sql.DB) { // SeedDatabase seeds the database with initial team data
-	teams := []string{"Chelsea", "Arsenal", "Manchester City", "Liverpool"} // List of fixed team names
-	rand.Seed(time.Now().UnixNano())                                        // Seed the random number generator
-	for _, name := range teams {                                            // Initialize each team with random strength of 1-4
-		strength := rand.Intn(4) + 1 // Random strength between 1 and 4
-		// Insert team strength into database
-		db.Exec("INSERT INTO teams (name, points, played, won, drawn, lost, gf, ga, gd, strength) VALUES (?, 0, 0, 0, 0, 0, 0, 0, 0, ?)", name, strength)
-	}
-
-	// Reset team stats to default values
-	db.Exec("UPDATE teams SET points = 0, played = 0, won = 0, drawn = 0, lost = 0, gf = 0, ga = 0, gd = 0 WHERE points IS NULL OR played IS NULL OR won IS NULL OR drawn IS NULL OR lost IS NULL OR gf IS NULL OR ga IS NULL OR gd IS NULL")
-}
+func SeedDatabase(store LeagueStore, teams []string, seed int64) error { // SeedDatabase seeds an empty league with the initial teams and first season
+	return store.Update(func(tx LeagueStore) error { // Teams and first season are stored together, so a failed seed leaves the league as it was
+		existing, err := tx.Teams() // Check whether teams already exist
+		if err != nil {
+			return err
+		}
 
-func PlayWeekMatches(db *sql.DB, week int) { // PlayWeekMatches simulates matches for the given week
-	var teams []Team
-	rows, err := db.Query("SELECT id, name, strength FROM teams") // Query to retrieve team data
-	if err != nil {
-		panic(err) // Panic if query fails
-	}
-	defer rows.Close() // Ensure rows are closed by end of function
+		log.Printf("Seeding league with seed %d", seed)
+		rng := rand.New(rand.NewSource(seed)) // Random source for strengths
+		if len(existing) == 0 {               // Keep the teams of an existing league
+			for _, name := range teams { // Initialize each team with random strength of 1-4
+				strength := rng.Intn(maxStrength-minStrength+1) + minStrength                                                                                   // Random strength between 1 and 4
+				team, err := tx.AddTeam(Team{Name: name, ShortCode: defaultShortCode(name, existing), Strength: strength, Attack: strength, Defense: strength}) // Attack and defense start at the strength, without home advantage
+				if err != nil {
+					return err
+				}
+				existing = append(existing, team) // Keep short codes unique
+			}
+		}
 
-	for rows.Next() { // Iterate over each row of SQL query
-		var team Team
-		if err := rows.Scan(&team.ID, &team.Name, &team.Strength); err != nil {
-			panic(err) // Panic if row scan fails
+		_, err = tx.Season()
+		if errors.Is(err, errNoSeason) { // Start the first season if none exists yet
+			_, err = StartSeason(tx, seed)
 		}
-		teams = append(teams, team) // Add team to list
-	}
+		return err
+	})
+}
 
-	if err := rows.Err(); err != nil {
-		panic(err) // Panic if row processing fails
-	}
+func PlayWeekMatches(store LeagueStore, seasonID, week int, rng *rand.Rand) error { // PlayWeekMatches simulates the scheduled fixtures for the given week in one transaction
+	return store.Update(func(tx LeagueStore) error { // Joins the caller's transaction when there is one
+		teams, err := tx.Teams() // Retrieve team strengths
+		if err != nil {
+			return err
+		}
+		rules, err := tx.Rules() // Whether teams play with their fixed strengths or their ratings
+		if err != nil {
+			return err
+		}
 
-	previousWeekMatches := getPreviousWeekMatches(db, week-1) // Get matches from the previous week
+		sides := make(map[int]matchSide) // Map of team ID to the side it plays with
+		for _, team := range teams {
+			sides[team.ID] = teamSide(team, rules) // Add team attack, defense and home advantage to map
+		}
 
-	rand.Seed(time.Now().UnixNano())                                                     // Seed the random number generator
-	rand.Shuffle(len(teams), func(i, j int) { teams[i], teams[j] = teams[j], teams[i] }) // Shuffle teams' order initially
+		fixtures, err := getWeekFixtures(tx, seasonID, week)
+		if err != nil {
+			return err
+		}
 
-	for i := 0; i < len(teams); i += 2 { // Generate 2 matches per week
-		if i+1 < len(teams) {
-			for isRepeatMatch(previousWeekMatches, teams[i].ID, teams[i+1].ID) { // Check if possible match is a repeat
-				rand.Shuffle(len(teams), func(i, j int) { teams[i], teams[j] = teams[j], teams[i] }) // Shuffle again if match is a repeat
+		for _, fixture := range fixtures { // Play each unplayed fixture scheduled for this week
+			err := playMatch(tx, rng, fixture.ID, fixture.HomeTeamID, fixture.AwayTeamID, week, sides[fixture.HomeTeamID], sides[fixture.AwayTeamID])
+			if err != nil {
+				return err
 			}
-			playMatch(db, teams[i].ID, teams[i+1].ID, week, teams[i].Strength, teams[i+1].Strength) // Play match between two teams
 		}
-	}
+		return recordRatings(tx, seasonID, week) // Keep the ratings reached after the week
+	})
 }
 
-func getPreviousWeekMatches(db *sql.DB, week int) []Match { // getPreviousWeekMatches returns the matches from the previous week
-	var matches []Match
-	rows, err := db.Query("SELECT home_team_id, away_team_id FROM matches WHERE week = ?", week) // Query to retrieve previous week matches
+func getWeekFixtures(store LeagueStore, seasonID, week int) ([]Match, error) { // getWeekFixtures returns the unplayed fixtures scheduled for the given week of a season
+	matches, err := store.Matches(seasonID, week) // Retrieve the week's matches in a stable order
 	if err != nil {
-		panic(err) // Panic if query fails
+		return nil, err
 	}
-	defer rows.Close() // Ensure rows are closed by end of function
 
-	for rows.Next() {
-		var match Match
-		if err := rows.Scan(&match.HomeTeamID, &match.AwayTeamID); err != nil {
-			panic(err) // Panic if row scan fails
+	var fixtures []Match
+	for _, match := range matches {
+		if !match.Played {
+			fixtures = append(fixtures, match) // Add fixture to list
 		}
-		matches = append(matches, match) // Add match to list
 	}
 
-	if err := rows.Err(); err != nil {
-		panic(err) // Panic if row processing fails
-	}
-
-	return matches
+	return fixtures, nil
 }
 
-func isRepeatMatch(previousWeekMatches []Match, homeTeamID, awayTeamID int) bool { // isRepeatMatch checks if a match between two teams is a repeat of a previous week's match
-	for _, match := range previousWeekMatches { // Check each match in previous week
-		if (match.HomeTeamID == homeTeamID && match.AwayTeamID == awayTeamID) || (match.HomeTeamID == awayTeamID && match.AwayTeamID == homeTeamID) { // Check if team IDs match
-			return true // Return true if match is a repeat
-		}
-	}
-	return false // Return true if match isn't a repeat
-}
-
-func playMatch(db *sql.DB, homeTeamID, awayTeamID, week, homeStrength, awayStrength int) { // playMatch simulates a match between two teams and updates database with the result
-	rand.Seed(time.Now().UnixNano()) // Seed the random number generator
-
-	homeScore := 0 // Initialize team scores
-	awayScore := 0
-
-	if homeStrength > awayStrength { // Calculate score based on team strengths
-		homeScore = rand.Intn(5)
-		awayScore = rand.Intn(4)
-		if awayScore > homeScore {
-			awayScore, homeScore = homeScore, awayScore // Swap scores if mismatch
-		}
-	} else if awayStrength > homeStrength {
-		homeScore = rand.Intn(4)
-		awayScore = rand.Intn(5)
-		if homeScore > awayScore {
-			awayScore, homeScore = homeScore, awayScore // Swap scores if mismatch
-		}
-	} else { // When strengths are equal
-		homeScore = rand.Intn(5)
-		awayScore = rand.Intn(5)
-	}
-
-	if homeScore == 0 && awayScore == 0 { // Ensure at least one goal is scored if both are 0
-		if homeStrength > awayStrength {
-			homeScore = rand.Intn(4) + 1
-		} else if homeStrength < awayStrength {
-			awayScore = rand.Intn(4) + 1
-		} else { // Equal chance if same strength
-			homeScore = rand.Intn(4) + 1
-			awayScore = rand.Intn(4) + 1
-		}
-	}
-
+func playMatch(store LeagueStore, rng *rand.Rand, matchID, homeTeamID, awayTeamID, week int, home, away matchSide) error { // playMatch simulates a fixture between two teams and updates database with the result and its timeline
 	match := Match{ // Initialize a Match object with values to be saved to database
+		ID:         matchID,
 		HomeTeamID: homeTeamID,
 		AwayTeamID: awayTeamID,
-		HomeScore:  homeScore,
-		AwayScore:  awayScore,
 		Week:       week,
+		Played:     true,
 	}
+	match, events := simulateMatch(rng, match, home, away) // Play minute by minute; the score is the goals of the timeline
 
-	saveMatch(db, match)
-	updateLeagueTable(db, match)
+	if err := saveMatch(store, match); err != nil {
+		return err
+	}
+	if err := store.SaveEvents(match.ID, events); err != nil {
+		return err
+	}
+	return updateLeagueTable(store, match)
 }
 
-func saveMatch(db *sql.DB, match Match) { // saveMatch saves a match result to the database
-	_, err := db.Exec("INSERT INTO matches (home_team_id, away_team_id, home_score, away_score, week) VALUES (?, ?, ?, ?, ?)",
-		match.HomeTeamID, match.AwayTeamID, match.HomeScore, match.AwayScore, match.Week) // Insert match data into matches table
-	if err != nil {
-		panic(err) // Panic if the query fails
+func saveMatch(store LeagueStore, match Match) error { // saveMatch saves a match result to its scheduled fixture in the database
+	return store.SaveMatch(match) // Record the result and mark the fixture as played
+}
+
+func updateLeagueTable(store LeagueStore, match Match) error { // updateLeagueTable updates the league table and both teams' ratings based on match result
+	if err := applyLeagueTable(store, match, 1); err != nil {
+		return err
 	}
+	return updateRatings(store, match)
 }
 
-func updateLeagueTable(db *sql.DB, match Match) { // updateLeagueTable updates the league table for each team based on match result
-	updateTeamStats(db, match.HomeTeamID, match.HomeScore, match.AwayScore) // Update stats for the home team
-	updateTeamStats(db, match.AwayTeamID, match.AwayScore, match.HomeScore) // Update stats for the away team
+func revertLeagueTable(store LeagueStore, match Match) error { // revertLeagueTable removes a previously applied match result from the league table; ratings are replayed separately
+	return applyLeagueTable(store, match, -1)
 }
 
-func updateTeamStats(db *sql.DB, teamID, goalsFor, goalsAgainst int) { // updateTeamStats updates the stats of a team based on a match
-	var team Team
-	err := db.QueryRow("SELECT id, points, played, won, drawn, lost, gf, ga, gd, strength FROM teams WHERE id = ?", teamID).
-		Scan(&team.ID, &team.Points, &team.Played, &team.Won, &team.Drawn, &team.Lost, &team.GF, &team.GA, &team.GD, &team.Strength) // Retrieve current team stats
+func applyLeagueTable(store LeagueStore, match Match, direction int) error { // applyLeagueTable applies (direction 1) or reverts (direction -1) a match result on both teams' stats under the league's points rules
+	rules, err := store.Rules() // Points for the result, including bonuses
 	if err != nil {
-		panic(err) // Panic if the query fails
+		return err
 	}
-
-	team.Played++               // Increment number of matches played
-	team.GF += goalsFor         // Update goals for of team
-	team.GA += goalsAgainst     // Update goals against of team
-	team.GD = team.GF - team.GA // Update goal difference of team
-
-	if goalsFor > goalsAgainst { // Update match result in terms of points: won, drawn, and lost
-		team.Won++
-		team.Points += 3
-	} else if goalsFor == goalsAgainst {
-		team.Drawn++
-		team.Points++
-	} else {
-		team.Lost++
+	if err := updateTeamStats(store, rules.Points, match.HomeTeamID, match.HomeScore, match.AwayScore, direction); err != nil { // Update stats for the home team
+		return err
 	}
+	return updateTeamStats(store, rules.Points, match.AwayTeamID, match.AwayScore, match.HomeScore, direction) // Update stats for the away team
+}
 
-	_, err = db.Exec("UPDATE teams SET points = ?, played = ?, won = ?, drawn = ?, lost = ?, gf = ?, ga = ?, gd = ?, strength = ? WHERE id = ?",
-		team.Points, team.Played, team.Won, team.Drawn, team.Lost, team.GF, team.GA, team.GD, team.Strength, team.ID) // Update the team stats in the database
+func updateTeamStats(store LeagueStore, points PointsRules, teamID, goalsFor, goalsAgainst, direction int) error { // updateTeamStats applies (direction 1) or reverts (direction -1) a match result on a team's stats
+	team, err := store.Team(teamID) // Retrieve current team stats
 	if err != nil {
-		panic(err) // Panic if the team stats update fails
+		return err
 	}
-}
 
-func predictStandings(db *sql.DB) []TeamPrediction { // Predicts the standings of the teams based on their points and goal difference (GD)
-	var teams []Team // Slice to store team data
+	team.Played += direction            // Update number of matches played
+	team.GF += direction * goalsFor     // Update goals for of team
+	team.GA += direction * goalsAgainst // Update goals against of team
+	team.GD = team.GF - team.GA         // Update goal difference of team
 
-	rows, err := db.Query("SELECT id, name, points, gd FROM teams") // Query to retrieve team data (points and GD) from the database
-	if err != nil {
-		panic(err) // Panic if the query fails
+	if goalsFor > goalsAgainst { // Update match result: won, drawn, and lost
+		team.Won += direction
+	} else if goalsFor == goalsAgainst {
+		team.Drawn += direction
+	} else {
+		team.Lost += direction
 	}
-	defer rows.Close() // Ensure the rows are closed after processing
+	team.Points += direction * points.matchPoints(goalsFor, goalsAgainst) // Update points, bonuses included
 
-	for rows.Next() { // Iterate through each row of the result
-		var team Team
-		if err := rows.Scan(&team.ID, &team.Name, &team.Points, &team.GD); err != nil {
-			panic(err) // Panic if row scanning fails
-		}
-		teams = append(teams, team) // Add the team data to the slice
-	}
+	return store.UpdateTeam(team) // Update the team stats in the database
+}
 
-	if err := rows.Err(); err != nil {
-		panic(err) // Panic if row processing fails
-	}
+func editMatchResult(store LeagueStore, seasonID, matchID, homeScore, awayScore int) (Match, error) { // editMatchResult replaces a played match's score in the current season and recomputes both teams' stats in one transaction
+	var match Match
+	err := store.Update(func(tx LeagueStore) error { // Run in a transaction so the table is never left half-updated
+		if _, err := openSeason(tx); err != nil {
+			return err // errSeasonArchived once the final standings are archived, so they keep matching the stored results
+		}
 
-	sort.Slice(teams, func(i, j int) bool { // Sort the teams slice by points and GD
-		if teams[i].Points == teams[j].Points {
-			return teams[i].GD > teams[j].GD // Sort by GD in descending order when points are equal
+		var err error
+		match, err = tx.Match(seasonID, matchID) // Retrieve the current result; unknown matches and matches of earlier seasons are not found
+		if err != nil {
+			return err
+		}
+		if !match.Played {
+			return errMatchNotPlayed // Only played results can be corrected
 		}
-		return teams[i].Points > teams[j].Points // Else, sort by points in descending order
-	})
 
-	totalPoints := 0             // Total points of all teams
-	totalPositiveGD := 0         // Total positive GD of all teams
-	totalNegativeGD := 0         // Total negative GD of all teams (handle negative GD case)
-	for _, team := range teams { // Iterate through each team to calculate total points and GD
-		totalPoints += team.Points
-		if team.GD >= 0 {
-			totalPositiveGD += team.GD
-		} else {
-			totalNegativeGD += -team.GD // Convert negative GD to positive for summing
+		if err := revertLeagueTable(tx, match); err != nil { // Remove the old result's effect on both teams
+			return err
 		}
-	}
 
-	var predictions []TeamPrediction
-	for _, team := range teams { // Iterate through each team to calculate predictions
-		var adjustedGD int // Adjusted GD based on +ve and -ve GD influence
-		if team.GD >= 0 {
-			adjustedGD = team.GD * totalPositiveGD / (totalPositiveGD + totalNegativeGD) // Adjust GD based on total positive GD if +ve
-		} else {
-			adjustedGD = -team.GD * totalNegativeGD / (totalPositiveGD + totalNegativeGD) // Adjust GD based on total negative GD if -ve
+		match.HomeScore = homeScore // Apply the corrected score
+		match.AwayScore = awayScore
+		if err := saveMatch(tx, match); err != nil {
+			return err
 		}
+		if err := applyLeagueTable(tx, match, 1); err != nil {
+			return err
+		}
+		events, err := tx.Events(seasonID, match.Week) // The week's timelines; only this match's goals move
+		if err != nil {
+			return err
+		}
+		var timeline []MatchEvent
+		for _, event := range events {
+			if event.MatchID == match.ID {
+				timeline = append(timeline, event)
+			}
+		}
+		if len(timeline) > 0 { // Imported results have no timeline to correct
+			rng := rand.New(rand.NewSource(int64(match.ID))) // Extra goals land on the same minutes whenever a match gets the same correction
+			if err := tx.SaveEvents(match.ID, moveGoalEvents(rng, match, timeline)); err != nil {
+				return err
+			}
+		}
+		return replayRatings(tx, seasonID) // Later results were rated from the old result's ratings
+	})
+	if err != nil {
+		return Match{}, err
+	}
+	return match, nil
+}
 
-		probability := float64(team.Points+adjustedGD) / float64(totalPoints) * 100 // Calculate probability including adjusted GD
-		predictions = append(predictions, TeamPrediction{team.Name, probability})   // Add prediction to the slice
+func predictStandings(store LeagueStore, seasonID int, rng *rand.Rand) ([]TeamPrediction, error) { // Predicts each team's championship chances by simulating the remaining fixtures
+	teams, err := store.Teams() // Retrieve the current table and team strengths
+	if err != nil {
+		return nil, err
 	}
 
-	normalizeFactor := 0.0 // Factor to normalize probabilities
-	for _, prediction := range predictions {
-		normalizeFactor += prediction.Probability // Sum each prediction probability
+	matches, err := store.Matches(seasonID, 0) // Retrieve the whole season's matches
+	if err != nil {
+		return nil, err
 	}
-	for i := range predictions { // Normalize probabilities to ensure they sum up to 100%
-		predictions[i].Probability = predictions[i].Probability * 100 / normalizeFactor
+
+	rules, err := store.Rules() // Simulated results earn points and are ranked like the league table
+	if err != nil {
+		return nil, err
 	}
 
-	return predictions
+	return simulateChampionship(teams, matches, rules, predictionRuns, rng), nil // Monte Carlo estimate of each team's title chances
 }
 
-func displayTableHTML(db *sql.DB) string { // Generates an HTML table displaying the league standings on Front-end
+func displayTableHTML(store LeagueStore) (string, error) { // Generates an HTML table displaying the league standings on Front-end
+	teams, err := leagueTable(store) // Retrieve team stats in table order
+	if err != nil {
+		return "", err
+	}
+
 	output := "<div class=\"section-box\">\n" // Start the section box in HTML
 	output += "<table>\n"                     // Start the table in HTML
 	// Table header row
 	output += "<tr><th>Team</th><th>PTS</th><th>P</th><th>W</th><th>D</th><th>L</th><th>GD</th><th>Str</th></tr>\n"
 
-	rows, err := db.Query("SELECT name, points, played, won, drawn, lost, gd, strength FROM teams WHERE played > 0") // Query to retrieve team stats where matches have been played
-	if err != nil {
-		log.Println(err)
-		return "" // Log error and return empty string if query fails
-	}
-	defer rows.Close() // Ensure rows are closed after processing
-
-	for rows.Next() { // Iterate through each row of the query result
-		var team Team
-		if err := rows.Scan(&team.Name, &team.Points, &team.Played, &team.Won, &team.Drawn, &team.Lost, &team.GD, &team.Strength); err != nil {
-			log.Println(err) // Log error if row scanning fails
-			continue         // Continue to the next row if there is an error
-		}
+	for _, team := range teams { // Iterate through each team
 		output += fmt.Sprintf("<tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td></tr>\n",
-			team.Name, team.Points, team.Played, team.Won, team.Drawn, team.Lost, team.GD, team.Strength) // Add a row to the table with the team stats
+			html.EscapeString(team.Name), team.Points, team.Played, team.Won, team.Drawn, team.Lost, team.GD, team.Strength) // Add a row to the table with the team stats
 	}
 
 	output += "</table>\n" // End the table in HTML
-	output += "</div>\n"   // End the section box in HTML
 
-	if err := rows.Err(); err != nil {
-		log.Println(err) // Log error if rrow processing fails
+	deductions, err := seasonDeductions(store) // Points taken from teams, noted under the table
+	if err != nil {
+		return "", err
+	}
+	for _, deduction := range deductions {
+		output += fmt.Sprintf("<small>%s: -%d PTS %s</small><br>\n", html.EscapeString(deduction.Team), deduction.Points, html.EscapeString(deduction.Reason))
 	}
 
-	return output // Return the HTML output
+	output += "</div>\n" // End the section box in HTML
+
+	return output, nil // Return the HTML output
 }
 
-func displayMatchResultsHTML(db *sql.DB, week int) string { // Generates an HTML section displaying match results for a specific week on Front-end
+func displayMatchResultsHTML(store LeagueStore, seasonID, week int) (string, error) { // Generates an HTML section displaying match results for a specific week on Front-end
+	matches, err := store.Matches(seasonID, week) // Retrieve the matches for the specified week
+	if err != nil {
+		return "", err
+	}
+	events, err := store.Events(seasonID, week) // Retrieve the timelines, for the minutes goals were scored in
+	if err != nil {
+		return "", err
+	}
 
 	output := "<div class=\"section-box\">"                                                // Start the section box in HTML
 	output += fmt.Sprintf("<b>%d%s Week Match Result</b>\n", week, getOrdinalSuffix(week)) // Add the week title with suffix
 
-	rows, err := db.Query("SELECT home_team_id, away_team_id, home_score, away_score FROM matches WHERE week = ?", week) // Query to retrieve match results for the specified week
-	if err != nil {
-		log.Println(err)
-		return "" // Log error and return empty string if query fails
-	}
-	defer rows.Close() // Ensure rows are closed after processing
-
-	for rows.Next() { // Iterate through each row of the query result
-		var homeTeamID, awayTeamID, homeScore, awayScore int
-		if err := rows.Scan(&homeTeamID, &awayTeamID, &homeScore, &awayScore); err != nil {
-			log.Println(err) // Log error if row scanning fails
-			continue         // Continue to next row if there is an error
+	for _, match := range matches { // Iterate through each match of the week
+		if !match.Played {
+			continue // Skip fixtures that have not been played yet
 		}
-		homeTeamName := getTeamName(db, homeTeamID) // Get the home team name
-		awayTeamName := getTeamName(db, awayTeamID) // Get the away team name
 		// Add match result to the output
-		output += fmt.Sprintf("%-20s %d - %-10d %-20s\n", homeTeamName, homeScore, awayScore, awayTeamName)
+		output += fmt.Sprintf("%-20s %d - %-10d %-20s\n", html.EscapeString(match.HomeTeam), match.HomeScore, match.AwayScore, html.EscapeString(match.AwayTeam))
+		output += goalTimelineHTML(match, events) // Followed by the minutes of its goals
 	}
 
 	output += "</div>\n" // End the section box in HTML
 
-	if err := rows.Err(); err != nil {
-		log.Println(err) // Log error if row processing fails
-	}
-
-	return output // Return the HTML output
+	return output, nil // Return the HTML output
 }
 
-func displayPredictionsHTML(db *sql.DB, week int) string {
+func displayPredictionsHTML(store LeagueStore, seasonID, week int, rng *rand.Rand) (string, error) {
 	output := "<div class=\"section-box\">\n"                                                              // Start the section box in HTML
 	output += fmt.Sprintf("<b>%d%s Week Predictions for Championship</b>\n", week, getOrdinalSuffix(week)) // Add the week title with suffix
 
-	predictions := predictStandings(db) // Calculate the predictions
-
-	sort.Slice(predictions, func(i, j int) bool { // Sort predictions by probability in descending order
-		return predictions[i].Probability > predictions[j].Probability
-	})
+	predictions, err := predictStandings(store, seasonID, rng) // Calculate the predictions
+	if err != nil {
+		return "", err
+	}
+	sortPredictions(predictions)
 
 	for idx, prediction := range predictions { // Iterate through each prediction to generate HTML output
-		output += fmt.Sprintf("<b>%d.</b> %-20s %.2f<br>\n", idx+1, prediction.Name, prediction.Probability)
+		output += fmt.Sprintf("<b>%d.</b> %-20s %.2f<br>\n", idx+1, html.EscapeString(prediction.Name), prediction.Probability)
 	}
 	output += "</div>\n" // End the section box in HTML
 
-	return output // Return the HTML output
+	output += displayPositionsHTML(predictions) // Add the finishing position probabilities
+
+	return output, nil // Return the HTML output
 }
 
-func getTeamName(db *sql.DB, teamID int) string { // Retrieves the name of a team given its ID
-	var name string
-	err := db.QueryRow("SELECT name FROM teams WHERE id = ?", teamID).Scan(&name) // Query to get the team name from the database
-	if err != nil {
-		panic(err) // Panic if the query fails
+func displayPositionsHTML(predictions []TeamPrediction) string { // Generates an HTML table of each team's finishing position probabilities and expected points
+	output := "<div class=\"section-box\">\n" // Start the section box in HTML
+	output += "<b>Finishing Position Probabilities (%)</b>\n"
+	output += "<table>\n"
+	output += "<tr><th>Team</th>" // Header row with one column per position
+	for position := range predictions {
+		output += fmt.Sprintf("<th>%d%s</th>", position+1, getOrdinalSuffix(position+1))
+	}
+	output += "<th>Exp PTS</th></tr>\n"
+
+	for _, prediction := range predictions { // One row per team
+		output += fmt.Sprintf("<tr><td>%s</td>", html.EscapeString(prediction.Name))
+		for _, probability := range prediction.Positions {
+			output += fmt.Sprintf("<td>%.1f</td>", probability)
+		}
+		output += fmt.Sprintf("<td>%.1f</td></tr>\n", prediction.ExpectedPoints)
 	}
-	return name
+
+	output += "</table>\n" // End the table in HTML
+	output += "</div>\n"   // End the section box in HTML
+
+	return output // Return the HTML output
+}
+
+func sortPredictions(predictions []TeamPrediction) { // sortPredictions orders predictions by title probability, then expected points, in descending order
+	sort.Slice(predictions, func(i, j int) bool {
+		if predictions[i].Probability == predictions[j].Probability {
+			return predictions[i].ExpectedPoints > predictions[j].ExpectedPoints
+		}
+		return predictions[i].Probability > predictions[j].Probability
+	})
 }
@@ -0,0 +1,259 @@
+package main
+
+import ( // Import required packages:
+	"errors"            // For matching error values
+	"math/rand"         // For fixed random sources
+	"net/http"          // For HTTP methods and status codes
+	"net/http/httptest" // For recording responses
+	"reflect"           // For comparing replayed results
+	"strings"           // For request bodies
+	"testing"           // For the test framework
+)
+
+func newTestStore(t *testing.T, teams ...string) LeagueStore { // newTestStore returns an in-memory league holding the given teams and their fixtures, so tests never touch league.db
+	t.Helper()
+	store := newMemoryStorage().League(defaultLeagueID)
+	if err := SeedDatabase(store, teams, 1); err != nil { // Fixed strengths and fixture seed
+		t.Fatal(err)
+	}
+	return store
+}
+
+func newTestServer(t *testing.T, teams ...string) *server { // newTestServer returns a server whose default league is held in memory with the given teams and their fixtures
+	t.Helper()
+	storage := newMemoryStorage()
+	if err := SeedDatabase(storage.League(defaultLeagueID), teams, 1); err != nil {
+		t.Fatal(err)
+	}
+	return &server{storage: storage}
+}
+
+func currentSeason(t *testing.T, store LeagueStore) Season { // currentSeason returns the season being played
+	t.Helper()
+	season, err := store.Season()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return season
+}
+
+func TestEditMatchResult(t *testing.T) { // Editing a result reverts the old score's effect on both teams and applies the new one
+	tests := []struct {
+		name             string
+		played, edited   [2]int // Home and away goals
+		homeWDL, awayWDL [3]int // Won, drawn and lost after the edit
+		homePoints       int
+		awayPoints       int
+	}{
+		{"win to draw", [2]int{2, 1}, [2]int{0, 0}, [3]int{0, 1, 0}, [3]int{0, 1, 0}, 1, 1},
+		{"win to loss", [2]int{2, 1}, [2]int{1, 3}, [3]int{0, 0, 1}, [3]int{1, 0, 0}, 0, 3},
+		{"draw to win", [2]int{1, 1}, [2]int{4, 0}, [3]int{1, 0, 0}, [3]int{0, 0, 1}, 3, 0},
+		{"same score", [2]int{0, 2}, [2]int{0, 2}, [3]int{0, 0, 1}, [3]int{1, 0, 0}, 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t, "Chelsea", "Arsenal")
+			season := currentSeason(t, store)
+			matches, err := store.Matches(season.ID, 1)
+			if err != nil || len(matches) != 1 {
+				t.Fatalf("week 1 fixtures = %v, %v", matches, err)
+			}
+			match := matches[0]
+			match.HomeScore, match.AwayScore, match.Played = tt.played[0], tt.played[1], true
+			if err := saveMatch(store, match); err != nil {
+				t.Fatal(err)
+			}
+			if err := updateLeagueTable(store, match); err != nil {
+				t.Fatal(err)
+			}
+
+			edited, err := editMatchResult(store, season.ID, match.ID, tt.edited[0], tt.edited[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if edited.HomeScore != tt.edited[0] || edited.AwayScore != tt.edited[1] || !edited.Played {
+				t.Errorf("edited match = %+v, want %d-%d", edited, tt.edited[0], tt.edited[1])
+			}
+
+			teams, err := store.Teams()
+			if err != nil {
+				t.Fatal(err)
+			}
+			stats := make(map[int]Team) // Teams by ID
+			for _, team := range teams {
+				stats[team.ID] = team
+			}
+			for _, side := range []struct {
+				team           Team
+				wdl            [3]int
+				points, gf, ga int
+			}{
+				{stats[match.HomeTeamID], tt.homeWDL, tt.homePoints, tt.edited[0], tt.edited[1]},
+				{stats[match.AwayTeamID], tt.awayWDL, tt.awayPoints, tt.edited[1], tt.edited[0]},
+			} {
+				team := side.team
+				if team.Played != 1 || [3]int{team.Won, team.Drawn, team.Lost} != side.wdl || team.Points != side.points ||
+					team.GF != side.gf || team.GA != side.ga || team.GD != side.gf-side.ga {
+					t.Errorf("%s = %+v, want 1 played, W/D/L %v, %d points, %d-%d goals", team.Name, team, side.wdl, side.points, side.gf, side.ga)
+				}
+			}
+		})
+	}
+}
+
+func TestEditMatchResultErrors(t *testing.T) { // Only played matches that exist can be edited, and only until the season is archived
+	store := newTestStore(t, "Chelsea", "Arsenal")
+	season := currentSeason(t, store)
+	matches, err := store.Matches(season.ID, 1)
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("week 1 fixtures = %v, %v", matches, err)
+	}
+
+	tests := []struct {
+		name    string
+		matchID int
+		want    error
+	}{
+		{"unknown match", 999, errMatchNotFound},
+		{"unplayed fixture", matches[0].ID, errMatchNotPlayed},
+	}
+	for _, tt := range tests {
+		if _, err := editMatchResult(store, season.ID, tt.matchID, 1, 0); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	for week := 1; week <= season.Weeks; week++ { // Finish and archive the season
+		if _, err := simulateWeek(store, season, week, weekRand(1, week)); err != nil {
+			t.Fatal(err)
+		}
+		season.CurrentWeek = week
+	}
+	if _, err := editMatchResult(store, season.ID, matches[0].ID, 1, 0); !errors.Is(err, errSeasonArchived) {
+		t.Errorf("archived season: error = %v, want %v", err, errSeasonArchived)
+	}
+}
+
+func TestChangeStrengthsHandler(t *testing.T) { // Attack and defense follow a new strength only when they are not sent with it
+	srv := newTestServer(t, "Chelsea", "Arsenal", "Everton")
+	body := `{"Chelsea": {"strength": 1, "attack": 4, "defense": 4, "homeAdvantage": 20}, "Arsenal": {"strength": 1}, "Everton": 4}`
+	w := httptest.NewRecorder()
+	jsonHandler(srv.changeStrengthsHandler)(w, httptest.NewRequest(http.MethodPost, "/changeStrengths", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+
+	teams, err := srv.storage.League(defaultLeagueID).Teams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][4]int{ // Strength, attack, defense and home advantage of each team
+		"Chelsea": {1, 4, 4, 20},
+		"Arsenal": {1, 1, 1, 0},
+		"Everton": {4, 4, 4, 0},
+	}
+	for _, team := range teams {
+		if got := [4]int{team.Strength, team.Attack, team.Defense, team.HomeAdvantage}; got != want[team.Name] {
+			t.Errorf("%s = %v, want %v", team.Name, got, want[team.Name])
+		}
+	}
+}
+
+func TestChangeStrengthsRating(t *testing.T) { // A new strength resets the rating only before the first week, so replayed ratings agree with it
+	srv := newTestServer(t, "Chelsea", "Arsenal")
+	store := srv.storage.League(defaultLeagueID)
+	change := func(body string) Team { // Posts new strengths and returns Chelsea afterwards
+		t.Helper()
+		w := httptest.NewRecorder()
+		jsonHandler(srv.changeStrengthsHandler)(w, httptest.NewRequest(http.MethodPost, "/changeStrengths", strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+		team, err := store.Team(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return team
+	}
+
+	season := currentSeason(t, store)
+	if chelsea := change(`{"Chelsea": 4}`); chelsea.Elo != eloForStrength(4) {
+		t.Errorf("rating before the first week = %v, want %v", chelsea.Elo, eloForStrength(4))
+	}
+	ratings, err := store.Ratings(season.ID)
+	if err != nil || len(ratings) == 0 || ratings[0].Rating != eloForStrength(4) {
+		t.Fatalf("starting ratings = %+v, %v, want Chelsea at %v", ratings, err, eloForStrength(4))
+	}
+
+	if _, err := simulateWeek(store, season, 1, weekRand(1, 1)); err != nil {
+		t.Fatal(err)
+	}
+	played, err := store.Team(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chelsea := change(`{"Chelsea": 1}`); chelsea.Strength != 1 || chelsea.Elo != played.Elo {
+		t.Errorf("after the first week = strength %d rated %v, want strength 1 still rated %v", chelsea.Strength, chelsea.Elo, played.Elo)
+	}
+
+	matches, err := store.Matches(season.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := editMatchResult(store, season.ID, matches[0].ID, matches[0].HomeScore, matches[0].AwayScore); err != nil { // Replays the ratings
+		t.Fatal(err)
+	}
+	if chelsea, err := store.Team(1); err != nil || chelsea.Elo != played.Elo {
+		t.Errorf("rating after a replay = %v, %v, want %v", chelsea.Elo, err, played.Elo)
+	}
+}
+
+func TestParseSeasonSeed(t *testing.T) { // A new season's seed comes from the seed parameter, or from -seed when it is missing
+	defer func(seed int64) { leagueSeed = seed }(leagueSeed)
+	leagueSeed = 7
+	for _, tt := range []struct {
+		query   string
+		want    int64
+		wantErr bool
+	}{
+		{"", 7, false},
+		{"?seed=42", 42, false},
+		{"?seed=abc", 0, true},
+	} {
+		seed, err := parseSeasonSeed(httptest.NewRequest(http.MethodPost, "/api/newSeason"+tt.query, nil))
+		if (err != nil) != tt.wantErr || (!tt.wantErr && seed != tt.want) {
+			t.Errorf("parseSeasonSeed(%q) = %d, %v, want %d (error %v)", tt.query, seed, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSeededSimulation(t *testing.T) { // The same seed plays the same season and predicts the same chances
+	play := func(seed int64) ([]Match, []TeamPrediction) { // Plays a whole season with one simulation seed
+		store := newTestStore(t, "Chelsea", "Arsenal", "Manchester City", "Liverpool")
+		season := currentSeason(t, store)
+		for week := 1; week <= 4; week++ { // Four of the six weeks, leaving fixtures to predict
+			if err := PlayWeekMatches(store, season.ID, week, weekRand(seed, week)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		matches, err := store.Matches(season.ID, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		predictions, err := predictStandings(store, season.ID, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return matches, predictions
+	}
+
+	matches, predictions := play(42)
+	replayed, replayedPredictions := play(42)
+	if !reflect.DeepEqual(matches, replayed) || !reflect.DeepEqual(predictions, replayedPredictions) {
+		t.Error("the same seed gave different results")
+	}
+	if other, _ := play(43); reflect.DeepEqual(matches, other) {
+		t.Error("a different seed gave the same results")
+	}
+}
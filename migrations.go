@@ -0,0 +1,298 @@
+package main
+
+import ( // Import required packages:
+	"database/sql" // For database operations
+	"fmt"          // For formatted errors
+	"log"          // For logging applied migrations
+	"strings"      // For the default tiebreaker list
+)
+
+type migration struct { // migration is one numbered schema change, applied once and recorded in schema_version
+	Version     int                 // Schema version reached after the migration
+	Description string              // Short description stored with the version
+	Up          func(*sql.Tx) error // Applies the change; must also succeed on databases created before migrations were tracked
+}
+
+var migrations = []migration{ // Every schema change in order; append new migrations with the next version number and never edit applied ones
+	{1, "create teams and matches tables", func(tx *sql.Tx) error {
+		return execAll(tx, `CREATE TABLE IF NOT EXISTS teams (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT,
+        points INTEGER DEFAULT 0,
+        played INTEGER DEFAULT 0,
+        won INTEGER DEFAULT 0,
+        drawn INTEGER DEFAULT 0,
+        lost INTEGER DEFAULT 0,
+        gf INTEGER DEFAULT 0,
+        ga INTEGER DEFAULT 0,
+        gd INTEGER DEFAULT 0,
+        strength INTEGER DEFAULT 1
+    );`, `CREATE TABLE IF NOT EXISTS matches (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        home_team_id INTEGER,
+        away_team_id INTEGER,
+        home_score INTEGER,
+        away_score INTEGER,
+        week INTEGER
+    );`)
+	}},
+	{2, "store scheduled fixtures as unplayed matches", func(tx *sql.Tx) error {
+		return addColumnIfMissing(tx, "matches", "played", "INTEGER DEFAULT 0")
+	}},
+	{3, "add seasons table and match season", func(tx *sql.Tx) error {
+		if err := execAll(tx, `CREATE TABLE IF NOT EXISTS seasons (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        current_week INTEGER DEFAULT 0,
+        weeks INTEGER
+    );`); err != nil {
+			return err
+		}
+		return addColumnIfMissing(tx, "matches", "season_id", "INTEGER")
+	}},
+	{4, "archive final standings of finished seasons", func(tx *sql.Tx) error {
+		if err := addColumnIfMissing(tx, "seasons", "archived", "INTEGER DEFAULT 0"); err != nil {
+			return err
+		}
+		return execAll(tx, `CREATE TABLE IF NOT EXISTS season_standings (
+        season_id INTEGER,
+        team_id INTEGER,
+        name TEXT,
+        points INTEGER,
+        played INTEGER,
+        won INTEGER,
+        drawn INTEGER,
+        lost INTEGER,
+        gf INTEGER,
+        ga INTEGER,
+        gd INTEGER,
+        strength INTEGER
+    );`)
+	}},
+	{5, "add leagues and scope teams and seasons to a league", func(tx *sql.Tx) error {
+		if err := execAll(tx, `CREATE TABLE IF NOT EXISTS leagues (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        code TEXT UNIQUE NOT NULL,
+        name TEXT,
+        created_at TEXT DEFAULT CURRENT_TIMESTAMP
+    );`); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT OR IGNORE INTO leagues (id, code, name) VALUES (?, ?, ?)", defaultLeagueID, defaultLeagueCode, defaultLeagueName); err != nil {
+			return err
+		}
+		// Existing teams and seasons belong to the default league; matches and standings belong to a league through their season
+		if err := addColumnIfMissing(tx, "teams", "league_id", fmt.Sprintf("INTEGER DEFAULT %d", defaultLeagueID)); err != nil {
+			return err
+		}
+		if err := addColumnIfMissing(tx, "seasons", "league_id", fmt.Sprintf("INTEGER DEFAULT %d", defaultLeagueID)); err != nil {
+			return err
+		}
+		return execAll(tx,
+			"CREATE INDEX IF NOT EXISTS teams_league_id ON teams (league_id)",
+			"CREATE INDEX IF NOT EXISTS seasons_league_id ON seasons (league_id)")
+	}},
+	{6, "add team short codes and removal, and generate fixtures when the first week is played", func(tx *sql.Tx) error {
+		columns := []struct{ table, column, definition string }{
+			{"teams", "short_code", "TEXT"},
+			{"teams", "removed", "INTEGER DEFAULT 0"},
+			{"season_standings", "short_code", "TEXT"},
+			{"seasons", "scheduled", "INTEGER DEFAULT 1"}, // Existing seasons already have their fixtures
+			{"seasons", "fixture_seed", "INTEGER DEFAULT 0"},
+		}
+		for _, c := range columns {
+			if err := addColumnIfMissing(tx, c.table, c.column, c.definition); err != nil {
+				return err
+			}
+		}
+		return backfillShortCodes(tx)
+	}},
+	{7, "store each league's tiebreakers", func(tx *sql.Tx) error {
+		return addColumnIfMissing(tx, "leagues", "tiebreakers", fmt.Sprintf("TEXT DEFAULT '%s'", strings.Join(defaultTiebreakers, ","))) // Existing leagues get the default tiebreakers
+	}},
+	{8, "store each league's points rules and point deductions", func(tx *sql.Tx) error {
+		columns := []struct{ column, definition string }{ // Existing leagues keep 3 points for a win and 1 for a draw, without bonuses
+			{"points_win", "INTEGER DEFAULT 3"},
+			{"points_draw", "INTEGER DEFAULT 1"},
+			{"points_loss", "INTEGER DEFAULT 0"},
+			{"goals_bonus_at", "INTEGER DEFAULT 0"},
+			{"goals_bonus", "INTEGER DEFAULT 0"},
+			{"narrow_loss_bonus", "INTEGER DEFAULT 0"},
+		}
+		for _, c := range columns {
+			if err := addColumnIfMissing(tx, "leagues", c.column, c.definition); err != nil {
+				return err
+			}
+		}
+		return execAll(tx, `CREATE TABLE IF NOT EXISTS point_deductions (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        season_id INTEGER,
+        team_id INTEGER,
+        points INTEGER,
+        reason TEXT,
+        created_at TEXT DEFAULT CURRENT_TIMESTAMP
+    );`, "CREATE INDEX IF NOT EXISTS point_deductions_season_id ON point_deductions (season_id)")
+	}},
+	{9, "add Elo ratings with their weekly history", func(tx *sql.Tx) error {
+		columns := []struct{ table, column, definition string }{
+			{"teams", "elo", "REAL"},
+			{"season_standings", "elo", "REAL"},
+			{"leagues", "elo_strength", "INTEGER DEFAULT 0"}, // Existing leagues keep playing with the fixed strengths
+		}
+		for _, c := range columns {
+			if err := addColumnIfMissing(tx, c.table, c.column, c.definition); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec("UPDATE teams SET elo = ? + ? * strength WHERE elo IS NULL", eloBase, eloPerStrength); err != nil { // Existing teams start from their strength
+			return err
+		}
+		return execAll(tx, `CREATE TABLE IF NOT EXISTS elo_ratings (
+        season_id INTEGER,
+        week INTEGER,
+        team_id INTEGER,
+        rating REAL
+    );`, "CREATE INDEX IF NOT EXISTS elo_ratings_season_id ON elo_ratings (season_id, week)")
+	}},
+	{10, "add attack, defense and home advantage to teams", func(tx *sql.Tx) error {
+		for _, table := range []string{"teams", "season_standings"} {
+			for _, c := range []struct{ column, definition string }{{"attack", "INTEGER"}, {"defense", "INTEGER"}, {"home_advantage", "INTEGER DEFAULT 0"}} {
+				if err := addColumnIfMissing(tx, table, c.column, c.definition); err != nil {
+					return err
+				}
+			}
+		}
+		_, err := tx.Exec("UPDATE teams SET attack = strength, defense = strength WHERE attack IS NULL") // Existing teams play as before: attack and defense at their strength, without home advantage
+		return err
+	}},
+	{11, "add the match_events table", func(tx *sql.Tx) error {
+		return execAll(tx, `CREATE TABLE IF NOT EXISTS match_events (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        match_id INTEGER,
+        minute INTEGER,
+        type TEXT,
+        team_id INTEGER
+    );`, "CREATE INDEX IF NOT EXISTS match_events_match_id ON match_events (match_id)")
+	}},
+}
+
+func migrateDatabase(db *sql.DB) error { // migrateDatabase applies every migration newer than the database's schema version
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+        version INTEGER PRIMARY KEY,
+        description TEXT,
+        applied_at TEXT DEFAULT CURRENT_TIMESTAMP
+    );`) // Table recording each applied migration
+	if err != nil {
+		return err
+	}
+
+	current, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations { // Apply pending migrations in order, each in its own transaction
+		if m.Version <= current {
+			continue // Already applied
+		}
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		log.Printf("Applied migration %d: %s", m.Version, m.Description)
+	}
+	return nil
+}
+
+func schemaVersion(db *sql.DB) (int, error) { // schemaVersion returns the highest applied migration, or 0 for a new or untracked database
+	var version int
+	err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version) // Query to retrieve the schema version
+	return version, err
+}
+
+func applyMigration(db *sql.DB, m migration) error { // applyMigration runs a migration and records its version in one transaction
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // Undo the migration unless it is committed
+
+	if err := m.Up(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO schema_version (version, description) VALUES (?, ?)", m.Version, m.Description); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func backfillShortCodes(tx *sql.Tx) error { // backfillShortCodes gives every team without a short code one derived from its name, unique within its league
+	rows, err := tx.Query("SELECT id, COALESCE(name, ''), league_id, COALESCE(short_code, '') FROM teams ORDER BY id") // Query to list every team
+	if err != nil {
+		return err
+	}
+	defer rows.Close() // Ensure rows are closed by end of function
+
+	type teamCode struct {
+		id, leagueID    int
+		name, shortCode string
+	}
+	var teams []teamCode
+	for rows.Next() {
+		var team teamCode
+		if err := rows.Scan(&team.id, &team.name, &team.leagueID, &team.shortCode); err != nil {
+			return err
+		}
+		teams = append(teams, team)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close() // Release the rows before updating the table
+
+	taken := make(map[int][]Team) // Short codes already used in each league
+	for _, team := range teams {
+		if team.shortCode == "" {
+			team.shortCode = defaultShortCode(team.name, taken[team.leagueID])
+			if _, err := tx.Exec("UPDATE teams SET short_code = ? WHERE id = ?", team.shortCode, team.id); err != nil {
+				return err
+			}
+		}
+		taken[team.leagueID] = append(taken[team.leagueID], Team{ShortCode: team.shortCode})
+	}
+	return nil
+}
+
+func execAll(db sqlExecutor, statements ...string) error { // execAll executes each statement in order, stopping at the first error
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addColumnIfMissing(db sqlExecutor, table, column, definition string) error { // addColumnIfMissing adds a column to an existing table unless it is already there
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table)) // Query to list the table's columns
+	if err != nil {
+		return err
+	}
+	defer rows.Close() // Ensure rows are closed by end of function
+
+	for rows.Next() {
+		var cid, notNull, primaryKey int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return err
+		}
+		if name == column {
+			return nil // Column already exists
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close() // Release the rows before altering the table
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)) // Add the missing column
+	return err
+}
@@ -0,0 +1,78 @@
+package main
+
+import ( // Import required packages:
+	"database/sql"  // For database operations
+	"path/filepath" // For the temporary database path
+	"testing"       // For the test framework
+)
+
+func TestMigrateBaselineDatabase(t *testing.T) { // A league.db created before migrations were tracked is brought up to date without losing data
+	db, err := sql.Open("sqlite", databaseDSN(filepath.Join(t.TempDir(), "league.db")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	err = execAll(db, `CREATE TABLE teams (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        name TEXT,
+        points INTEGER DEFAULT 0,
+        played INTEGER DEFAULT 0,
+        won INTEGER DEFAULT 0,
+        drawn INTEGER DEFAULT 0,
+        lost INTEGER DEFAULT 0,
+        gf INTEGER DEFAULT 0,
+        ga INTEGER DEFAULT 0,
+        gd INTEGER DEFAULT 0,
+        strength INTEGER DEFAULT 1
+    );`, `CREATE TABLE matches (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        home_team_id INTEGER,
+        away_team_id INTEGER,
+        home_score INTEGER,
+        away_score INTEGER,
+        week INTEGER
+    );`, // Schema of the original SetupDatabase
+		"INSERT INTO teams (name, points, played, won, gf, ga, gd, strength) VALUES ('Chelsea', 3, 1, 1, 2, 0, 2, 4)",
+		"INSERT INTO teams (name, played, lost, gf, ga, gd, strength) VALUES ('Arsenal', 1, 1, 0, 2, -2, 2)",
+		"INSERT INTO matches (home_team_id, away_team_id, home_score, away_score, week) VALUES (1, 2, 2, 0, 1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for run := 1; run <= 2; run++ { // The second run finds nothing to apply
+		if err := migrateDatabase(db); err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+		version, err := schemaVersion(db)
+		if err != nil || version != migrations[len(migrations)-1].Version {
+			t.Fatalf("run %d: schema version %d, %v, want %d", run, version, err, migrations[len(migrations)-1].Version)
+		}
+		var applied int
+		if err := db.QueryRow("SELECT COUNT(*) FROM schema_version").Scan(&applied); err != nil || applied != len(migrations) {
+			t.Errorf("run %d: %d migrations recorded, %v, want %d", run, applied, err, len(migrations))
+		}
+	}
+
+	for table, columns := range map[string][]string{
+		"matches": {"played", "season_id"},
+		"seasons": {"current_week", "weeks", "archived"},
+	} {
+		for _, column := range columns {
+			var found int
+			err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&found)
+			if err != nil || found != 1 {
+				t.Errorf("%s.%s missing after migration (%v)", table, column, err)
+			}
+		}
+	}
+
+	var name string
+	var points, homeScore, awayScore int
+	if err := db.QueryRow("SELECT name, points FROM teams WHERE id = 1").Scan(&name, &points); err != nil || name != "Chelsea" || points != 3 {
+		t.Errorf("team 1 = %q with %d points, %v, want Chelsea with 3", name, points, err)
+	}
+	if err := db.QueryRow("SELECT home_score, away_score FROM matches WHERE id = 1").Scan(&homeScore, &awayScore); err != nil || homeScore != 2 || awayScore != 0 {
+		t.Errorf("match 1 = %d-%d, %v, want 2-0", homeScore, awayScore, err)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import ( // Import required packages:
+	"math/rand" // For the simulation's random source
+)
+
+const predictionRuns = 10000 // Number of simulated season endings used to estimate championship chances
+
+func simulateChampionship(teams []Team, matches []Match, rules Rules, runs int, rng *rand.Rand) []TeamPrediction { // simulateChampionship plays out the season's unplayed matches many times and returns each team's finishing position probabilities
+	table := newStandingsTable(teams, matches, rules.Points) // Current table, with the played matches for the head-to-head tiebreakers
+	index := table.index                                     // Map of team ID to position in the teams slice
+	current := append([]seasonStanding{}, table.teams...)
+	played := len(table.matches)
+
+	var fixtures []Match // Fixtures still to be played
+	for _, match := range matches {
+		if !match.Played {
+			fixtures = append(fixtures, match)
+		}
+	}
+
+	if len(fixtures) == 0 {
+		runs = 1 // With no fixtures left the final table is already known
+	}
+
+	positions := make([][]float64, len(teams)) // Number of simulated seasons each team finishes in each position
+	for i := range positions {
+		positions[i] = make([]float64, len(teams))
+	}
+	totalPoints := make([]float64, len(teams)) // Sum of final points over all runs
+	for run := 0; run < runs; run++ {
+		copy(table.teams, current) // Start every run from the current table
+		table.matches = table.matches[:played]
+
+		for _, fixture := range fixtures { // Play out every remaining fixture with the match engine
+			home, away := index[fixture.HomeTeamID], index[fixture.AwayTeamID]
+			homeScore, awayScore := simulateScore(rng, teamSide(teams[home], rules), teamSide(teams[away], rules))
+			applySimulatedResult(&table.teams[home], homeScore, awayScore, rules.Points)
+			applySimulatedResult(&table.teams[away], awayScore, homeScore, rules.Points)
+			table.teams[away].AwayGF += awayScore
+
+			fixture.HomeScore, fixture.AwayScore, fixture.Played = homeScore, awayScore, true
+			table.matches = append(table.matches, fixture) // Simulated results count for the head-to-head tiebreakers
+		}
+
+		start := 0
+		for _, group := range table.rank(rules.Tiebreakers) { // Lots are not drawn, so teams only lots could separate share the positions they occupy equally
+			share := 1 / float64(len(group))
+			for _, i := range group {
+				for position := start; position < start+len(group); position++ {
+					positions[i][position] += share
+				}
+			}
+			start += len(group)
+		}
+
+		for i := range table.teams {
+			totalPoints[i] += float64(table.teams[i].Points)
+		}
+	}
+
+	remaining := make([]int, len(teams)) // Number of fixtures each team still has to play
+	for _, fixture := range fixtures {
+		remaining[index[fixture.HomeTeamID]]++
+		remaining[index[fixture.AwayTeamID]]++
+	}
+
+	predictions := make([]TeamPrediction, len(teams))
+	for i, team := range teams {
+		for position := range positions[i] { // Convert position counts to percentages
+			positions[i][position] = positions[i][position] / float64(runs) * 100
+		}
+
+		probability := positions[i][0]
+		if isEliminated(teams, remaining, i, rules.Points.maxMatchPoints()) {
+			probability = 0 // Cannot reach the leader's current points
+		} else if hasClinched(teams, remaining, i, rules.Points.maxMatchPoints()) {
+			probability = 100 // No other team can reach this team's current points
+		}
+
+		predictions[i] = TeamPrediction{
+			Name:           team.Name,
+			Probability:    probability,
+			Positions:      positions[i],
+			ExpectedPoints: totalPoints[i] / float64(runs),
+		}
+	}
+
+	return predictions
+}
+
+func applySimulatedResult(standing *seasonStanding, goalsFor, goalsAgainst int, points PointsRules) { // applySimulatedResult adds one simulated result to a team's running totals
+	standing.GF += goalsFor
+	standing.GD += goalsFor - goalsAgainst
+	standing.Points += points.matchPoints(goalsFor, goalsAgainst)
+}
+
+func isEliminated(teams []Team, remaining []int, i, maxMatchPoints int) bool { // isEliminated reports whether some team already has more points than team i can still reach
+	maxPoints := teams[i].Points + maxMatchPoints*remaining[i]
+	for j, team := range teams {
+		if j != i && team.Points > maxPoints {
+			return true
+		}
+	}
+	return false
+}
+
+func hasClinched(teams []Team, remaining []int, i, maxMatchPoints int) bool { // hasClinched reports whether no other team can still reach team i's current points
+	for j, team := range teams {
+		if j != i && team.Points+maxMatchPoints*remaining[j] >= teams[i].Points {
+			return false
+		}
+	}
+	return true
+}
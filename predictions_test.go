@@ -0,0 +1,117 @@
+package main
+
+import ( // Import required packages:
+	"math"      // For comparing probabilities
+	"math/rand" // For a fixed random source
+	"testing"   // For the test framework
+)
+
+func TestEliminatedAndClinched(t *testing.T) { // A team is out once another has more points than it can reach, and has clinched once no other team can reach its points
+	tests := []struct {
+		name       string
+		points     []int
+		remaining  []int
+		eliminated []bool
+		clinched   []bool
+	}{
+		{"open race", []int{6, 4, 3}, []int{2, 2, 2}, []bool{false, false, false}, []bool{false, false, false}},
+		{"one team out", []int{9, 7, 2}, []int{1, 2, 1}, []bool{false, false, true}, []bool{false, false, false}},
+		{"title clinched", []int{12, 3, 0}, []int{1, 1, 2}, []bool{false, true, true}, []bool{true, false, false}},
+		{"level with nothing left", []int{6, 6}, []int{0, 0}, []bool{false, false}, []bool{false, false}},
+		{"exactly reachable", []int{9, 6}, []int{0, 1}, []bool{false, false}, []bool{false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teams := make([]Team, len(tt.points))
+			for i, points := range tt.points {
+				teams[i] = Team{ID: i + 1, Points: points}
+			}
+			for i := range teams {
+				if got := isEliminated(teams, tt.remaining, i, 3); got != tt.eliminated[i] {
+					t.Errorf("isEliminated(team %d) = %v, want %v", i+1, got, tt.eliminated[i])
+				}
+				if got := hasClinched(teams, tt.remaining, i, 3); got != tt.clinched[i] {
+					t.Errorf("hasClinched(team %d) = %v, want %v", i+1, got, tt.clinched[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSimulateChampionship(t *testing.T) { // Eliminated teams show exactly 0%, a team that has clinched exactly 100%, and the chances add up to 100%
+	tests := []struct {
+		name     string
+		teams    []Team
+		fixtures []Match
+		want     map[int]float64 // Exact probability expected for some teams, by team ID
+	}{
+		{
+			name:     "title clinched",
+			teams:    []Team{{ID: 1, Points: 12, Strength: 1}, {ID: 2, Points: 3, Strength: 4}, {ID: 3, Points: 0, Strength: 4}},
+			fixtures: []Match{{HomeTeamID: 2, AwayTeamID: 3}},
+			want:     map[int]float64{1: 100, 2: 0, 3: 0},
+		},
+		{
+			name:     "one team out of the race",
+			teams:    []Team{{ID: 1, Points: 9, Strength: 2}, {ID: 2, Points: 7, Strength: 2}, {ID: 3, Points: 2, Strength: 4}},
+			fixtures: []Match{{HomeTeamID: 1, AwayTeamID: 3}, {HomeTeamID: 2, AwayTeamID: 3}, {HomeTeamID: 2, AwayTeamID: 1}},
+			want:     map[int]float64{3: 0},
+		},
+		{
+			name:  "level with nothing left to play",
+			teams: []Team{{ID: 1, Points: 6, GD: 2, GF: 4}, {ID: 2, Points: 6, GD: 2, GF: 4}},
+			want:  map[int]float64{1: 50, 2: 50},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			predictions := simulateChampionship(tt.teams, tt.fixtures, defaultRules(), 2000, rand.New(rand.NewSource(1)))
+			total := 0.0
+			for i, prediction := range predictions {
+				total += prediction.Probability
+				if want, ok := tt.want[tt.teams[i].ID]; ok && prediction.Probability != want {
+					t.Errorf("team %d: probability %v, want exactly %v", tt.teams[i].ID, prediction.Probability, want)
+				}
+			}
+			if math.Abs(total-100) > 1e-6 {
+				t.Errorf("probabilities add up to %v, want 100", total)
+			}
+		})
+	}
+}
+
+func TestPredictedPositions(t *testing.T) { // Every team finishes somewhere and every position is taken, and expected points stay within reach
+	teams := []Team{{ID: 1, Points: 9, Strength: 2}, {ID: 2, Points: 7, Strength: 2}, {ID: 3, Points: 2, Strength: 4}}
+	fixtures := []Match{{HomeTeamID: 1, AwayTeamID: 3}, {HomeTeamID: 2, AwayTeamID: 3}, {HomeTeamID: 2, AwayTeamID: 1}}
+	remaining := []int{2, 2, 2} // Fixtures left for each team
+
+	predictions := simulateChampionship(teams, fixtures, defaultRules(), 2000, rand.New(rand.NewSource(1)))
+	columns := make([]float64, len(teams)) // Sum of each position over the teams
+	for i, prediction := range predictions {
+		if len(prediction.Positions) != len(teams) {
+			t.Fatalf("team %d has %d positions, want %d", i+1, len(prediction.Positions), len(teams))
+		}
+		row := 0.0
+		for position, probability := range prediction.Positions {
+			row += probability
+			columns[position] += probability
+		}
+		if math.Abs(row-100) > 1e-6 {
+			t.Errorf("team %d finishes somewhere with probability %v, want 100", i+1, row)
+		}
+		if prediction.Positions[0] != prediction.Probability {
+			t.Errorf("team %d: 1st place %v differs from title probability %v", i+1, prediction.Positions[0], prediction.Probability)
+		}
+		low, high := float64(teams[i].Points), float64(teams[i].Points+3*remaining[i])
+		if prediction.ExpectedPoints < low || prediction.ExpectedPoints > high {
+			t.Errorf("team %d expects %v points, want %v to %v", i+1, prediction.ExpectedPoints, low, high)
+		}
+	}
+	for position, total := range columns {
+		if math.Abs(total-100) > 1e-6 {
+			t.Errorf("position %d is taken with probability %v, want 100", position+1, total)
+		}
+	}
+}
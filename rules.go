@@ -0,0 +1,193 @@
+package main
+
+import ( // Import required packages:
+	"encoding/json" // For JSON decoding
+	"errors"        // For creating error values
+	"fmt"           // For formatted errors
+	"io"            // For reading the request body
+	"net/http"      // For HTTP request handling
+	"slices"        // For looking up tiebreakers
+)
+
+var errInvalidRules = errors.New("invalid rules") // Wrapped with the rule that is not accepted
+
+const (
+	maxRulePoints   = 10      // Most points a rule may award
+	maxGoalsBonusAt = 20      // Highest goal count a goals bonus may require
+	maxRulesSize    = 1 << 16 // Largest PUT /api/rules body
+)
+
+type Rules struct { // Rules are the competition rules of a league
+	Tiebreakers []string    `json:"tiebreakers"` // Criteria that separate teams level on points, in order, e.g. gd, gf, h2h_points
+	Points      PointsRules `json:"points"`      // Points earned for each match
+	EloStrength bool        `json:"eloStrength"` // Whether matches are simulated from the teams' Elo ratings instead of their fixed strengths
+}
+
+type PointsRules struct { // PointsRules are the points a team earns for a match result, including bonus points
+	Win             int `json:"win"`             // Points for a win
+	Draw            int `json:"draw"`            // Points for a draw
+	Loss            int `json:"loss"`            // Points for a loss
+	GoalsBonusAt    int `json:"goalsBonusAt"`    // Goals a team must score in a match to earn GoalsBonus (0 for no goals bonus)
+	GoalsBonus      int `json:"goalsBonus"`      // Bonus points for scoring at least GoalsBonusAt goals
+	NarrowLossBonus int `json:"narrowLossBonus"` // Bonus points for losing by one goal
+}
+
+func defaultRules() Rules { // defaultRules returns the rules of a new league
+	return Rules{Tiebreakers: slices.Clone(defaultTiebreakers), Points: PointsRules{Win: 3, Draw: 1}} // Matches use the fixed strengths
+}
+
+func (p PointsRules) matchPoints(goalsFor, goalsAgainst int) int { // matchPoints returns the points a team earns for a result, bonuses included
+	points := p.Loss
+	if goalsFor > goalsAgainst {
+		points = p.Win
+	} else if goalsFor == goalsAgainst {
+		points = p.Draw
+	} else if goalsAgainst-goalsFor == 1 {
+		points += p.NarrowLossBonus
+	}
+	if p.GoalsBonusAt > 0 && goalsFor >= p.GoalsBonusAt {
+		points += p.GoalsBonus
+	}
+	return points
+}
+
+func (p PointsRules) maxMatchPoints() int { // maxMatchPoints returns the most points a team can earn in one match
+	points := max(p.Win, p.Draw, p.Loss+p.NarrowLossBonus)
+	if p.GoalsBonusAt > 0 {
+		points += p.GoalsBonus
+	}
+	return points
+}
+
+func (srv *server) rulesAPIHandler(w http.ResponseWriter, r *http.Request) { // rulesAPIHandler sends (GET) or changes (PUT) the competition rules of a league
+	league, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		rules, err := store.Rules()
+		if err != nil {
+			storeError(w, r, err, "Failed to fetch rules") // Return error if query fails
+			return
+		}
+		writeJSON(w, rules)
+		return
+	}
+	if r.Method != http.MethodPut {
+		writeError(w, r, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed") // Only GET and PUT are supported
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRulesSize)) // Decoded over the current rules, so rules left out keep their value
+	if err != nil || !json.Valid(body) {
+		writeError(w, r, http.StatusBadRequest, "invalid_input", "Invalid input") // Return error for invalid body
+		return
+	}
+
+	unlock, ok := srv.lockLeague(w, r, league.ID) // Only one request may change the league at a time
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	var rules Rules
+	err = store.Update(func(tx LeagueStore) error { // Read, save and recompute the table in one transaction
+		current, err := tx.Rules()
+		if err != nil {
+			return err
+		}
+		rules = current
+		rules.Tiebreakers = slices.Clone(current.Tiebreakers) // Replaced, not overwritten in place, by a tiebreakers list in the body
+		if err := json.Unmarshal(body, &rules); err != nil {
+			return fmt.Errorf("%w: %v", errInvalidRules, err) // Wrong types, e.g. a string for a number of points
+		}
+		if rules, err = checkRules(rules); err != nil {
+			return err
+		}
+		if err := tx.SetRules(rules); err != nil {
+			return err
+		}
+		if rules.Points != current.Points {
+			return recomputeTable(tx) // Results already played earn points under the new rules
+		}
+		return nil
+	})
+	if err != nil {
+		storeError(w, r, err, "Failed to save rules") // 400 for rules that are not accepted
+		return
+	}
+
+	writeJSON(w, rules)
+}
+
+func checkRules(rules Rules) (Rules, error) { // checkRules rejects points out of range, unknown or repeated tiebreakers and tiebreakers after the drawing of lots
+	p := rules.Points
+	for _, points := range []int{p.Win, p.Draw, p.Loss, p.GoalsBonus, p.NarrowLossBonus} {
+		if points < 0 || points > maxRulePoints {
+			return Rules{}, fmt.Errorf("%w: points must be between 0 and %d", errInvalidRules, maxRulePoints)
+		}
+	}
+	if p.Win < p.Draw || p.Draw < p.Loss {
+		return Rules{}, fmt.Errorf("%w: a win must earn at least as many points as a draw, and a draw at least as many as a loss", errInvalidRules)
+	}
+	if p.GoalsBonusAt < 0 || p.GoalsBonusAt > maxGoalsBonusAt {
+		return Rules{}, fmt.Errorf("%w: goalsBonusAt must be between 0 and %d", errInvalidRules, maxGoalsBonusAt)
+	}
+
+	checked := []string{} // Empty rather than nil so a league ranked by points alone encodes as []
+	for _, tiebreaker := range rules.Tiebreakers {
+		if !slices.Contains(knownTiebreakers, tiebreaker) {
+			return Rules{}, fmt.Errorf("%w: unknown tiebreaker %q, use one of %v", errInvalidRules, tiebreaker, knownTiebreakers)
+		}
+		if slices.Contains(checked, tiebreaker) {
+			return Rules{}, fmt.Errorf("%w: tiebreaker %q is listed twice", errInvalidRules, tiebreaker)
+		}
+		if slices.Contains(checked, tiebreakLots) {
+			return Rules{}, fmt.Errorf("%w: %s must be the last tiebreaker", errInvalidRules, tiebreakLots)
+		}
+		checked = append(checked, tiebreaker)
+	}
+	rules.Tiebreakers = checked
+	return rules, nil
+}
+
+func recomputeTable(store LeagueStore) error { // recomputeTable rebuilds the current season's table from its played results and point deductions; archived seasons keep their final standings
+	season, err := store.Season()
+	if errors.Is(err, errNoSeason) {
+		return nil // Nothing has been played yet
+	}
+	if err != nil {
+		return err
+	}
+	if season.Archived {
+		return nil
+	}
+
+	if err := store.ResetTeamStats(); err != nil {
+		return err
+	}
+	matches, err := store.Matches(season.ID, 0)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		if !match.Played {
+			continue
+		}
+		if err := applyLeagueTable(store, match, 1); err != nil { // Ratings do not depend on points and are kept
+			return err
+		}
+	}
+
+	deductions, err := store.Deductions(season.ID)
+	if err != nil {
+		return err
+	}
+	for _, deduction := range deductions {
+		if err := adjustPoints(store, deduction.TeamID, -deduction.Points); err != nil {
+			return err
+		}
+	}
+	return nil
+}
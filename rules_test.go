@@ -0,0 +1,62 @@
+package main
+
+import ( // Import required packages:
+	"testing" // For the test framework
+)
+
+var bonusPoints = PointsRules{Win: 4, Draw: 2, GoalsBonusAt: 4, GoalsBonus: 1, NarrowLossBonus: 1} // Rugby-style points with both bonuses
+
+func TestMatchPoints(t *testing.T) { // Results earn the win, draw or loss points plus any bonus they qualify for
+	tests := []struct {
+		name                   string
+		rules                  PointsRules
+		goalsFor, goalsAgainst int
+		want                   int
+	}{
+		{"default win", defaultRules().Points, 2, 0, 3},
+		{"default draw", defaultRules().Points, 1, 1, 1},
+		{"default loss", defaultRules().Points, 0, 1, 0},
+		{"default rules have no bonus", defaultRules().Points, 5, 4, 3},
+		{"win", bonusPoints, 2, 0, 4},
+		{"win with goals bonus", bonusPoints, 4, 0, 5},
+		{"draw with goals bonus", bonusPoints, 4, 4, 3},
+		{"narrow loss", bonusPoints, 1, 2, 1},
+		{"narrow loss with goals bonus", bonusPoints, 4, 5, 2},
+		{"heavy loss", bonusPoints, 0, 3, 0},
+		{"bonus disabled with GoalsBonusAt 0", PointsRules{Win: 3, Draw: 1, GoalsBonus: 5}, 0, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.matchPoints(tt.goalsFor, tt.goalsAgainst); got != tt.want {
+				t.Errorf("matchPoints(%d, %d) = %d, want %d", tt.goalsFor, tt.goalsAgainst, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxMatchPoints(t *testing.T) { // The most points of one match bounds every result, bonuses included
+	tests := []struct {
+		name  string
+		rules PointsRules
+		want  int
+	}{
+		{"default rules", defaultRules().Points, 3},
+		{"both bonuses", bonusPoints, 5},
+		{"narrow loss above a draw", PointsRules{Win: 2, Draw: 0, Loss: 1, NarrowLossBonus: 2}, 3},
+	}
+
+	for _, tt := range tests {
+		got := tt.rules.maxMatchPoints()
+		if got != tt.want {
+			t.Errorf("%s: maxMatchPoints() = %d, want %d", tt.name, got, tt.want)
+		}
+		for goalsFor := 0; goalsFor <= 6; goalsFor++ {
+			for goalsAgainst := 0; goalsAgainst <= 6; goalsAgainst++ {
+				if points := tt.rules.matchPoints(goalsFor, goalsAgainst); points > got {
+					t.Errorf("%s: %d-%d earns %d points, more than maxMatchPoints() %d", tt.name, goalsFor, goalsAgainst, points, got)
+				}
+			}
+		}
+	}
+}
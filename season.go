@@ -0,0 +1,70 @@
+package main
+
+import ( // Import required packages:
+	"errors"    // For matching error values
+	"math/rand" // For the season's fixture order
+)
+
+func StartSeason(store LeagueStore, fixtureSeed int64) (Season, error) { // StartSeason archives the current season, resets the table and starts a new season with its full fixture list
+	var season Season
+	err := store.Update(func(tx LeagueStore) error { // One transaction, so a failed start never leaves the current season's table wiped; joins the caller's transaction when there is one
+		var err error
+		if season, err = addSeason(tx, fixtureSeed); err != nil {
+			return err
+		}
+		season, err = scheduleSeason(tx, season)
+		return err
+	})
+	if err != nil {
+		return Season{}, err
+	}
+	return season, nil
+}
+
+func addSeason(store LeagueStore, fixtureSeed int64) (Season, error) { // addSeason archives the current season, resets the table and adds a new season without fixtures
+	current, err := store.Season()
+	if err == nil {
+		if err := store.ArchiveSeason(current.ID); err != nil { // Keep the previous season's table, even if it was not finished
+			return Season{}, err
+		}
+	} else if !errors.Is(err, errNoSeason) {
+		return Season{}, err
+	}
+
+	if err := store.ResetTeamStats(); err != nil { // Reset team stats for the new season, keeping names and strengths
+		return Season{}, err
+	}
+
+	teams, err := store.Teams() // Retrieve the teams to schedule
+	if err != nil {
+		return Season{}, err
+	}
+
+	if _, err := store.AddSeason(seasonLength(len(teams)), fixtureSeed); err != nil { // Start the season state at week 0 with a length derived from the number of teams
+		return Season{}, err
+	}
+	return store.Season()
+}
+
+func scheduleSeason(store LeagueStore, season Season) (Season, error) { // scheduleSeason generates the fixtures of a season that has not started from its fixture seed and the current teams, replacing any generated before
+	teams, err := store.Teams()
+	if err != nil {
+		return Season{}, err
+	}
+
+	season.Weeks = seasonLength(len(teams))
+	season.Scheduled = true
+	if err := store.RemoveFixtures(season.ID); err != nil { // Fixtures generated for an earlier set of teams
+		return Season{}, err
+	}
+	if err := GenerateFixtures(store, season.ID, rand.New(rand.NewSource(season.FixtureSeed))); err != nil { // Schedule the full season of fixtures for the teams
+		return Season{}, err
+	}
+	if err := store.SetSchedule(season.ID, season.Weeks, true); err != nil {
+		return Season{}, err
+	}
+	if err := recordRatings(store, season.ID, 0); err != nil { // Ratings at the start of the season, which edited results are replayed from
+		return Season{}, err
+	}
+	return withFinished(season), nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import ( // Import required packages:
+	"errors"            // For matching error values
+	"net/http"          // For HTTP status codes
+	"net/http/httptest" // For recording responses
+	"reflect"           // For comparing standings
+	"testing"           // For the test framework
+)
+
+func TestCheckNextWeek(t *testing.T) { // Only the week after the current one can be played, and not once the season has finished
+	tests := []struct {
+		name       string
+		season     Season
+		query      string
+		wantWeek   int
+		wantStatus int // Status of the rejection, or 0 when the week can be played
+	}{
+		{"next week by default", Season{CurrentWeek: 2, Weeks: 6}, "", 3, 0},
+		{"next week requested", Season{CurrentWeek: 2, Weeks: 6}, "?week=3", 3, 0},
+		{"first week", Season{Weeks: 6}, "?week=1", 1, 0},
+		{"replayed week", Season{CurrentWeek: 2, Weeks: 6}, "?week=2", 0, http.StatusConflict},
+		{"skipped week", Season{CurrentWeek: 2, Weeks: 6}, "?week=5", 0, http.StatusConflict},
+		{"invalid week", Season{CurrentWeek: 2, Weeks: 6}, "?week=two", 0, http.StatusBadRequest},
+		{"finished season", Season{CurrentWeek: 6, Weeks: 6, Finished: true}, "", 0, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			week, ok := checkNextWeek(w, httptest.NewRequest(http.MethodGet, "/simulate"+tt.query, nil), tt.season)
+			if tt.wantStatus != 0 {
+				if ok || w.Code != tt.wantStatus {
+					t.Errorf("checkNextWeek() = %v with status %d, want rejection with %d", ok, w.Code, tt.wantStatus)
+				}
+				return
+			}
+			if !ok || week != tt.wantWeek {
+				t.Errorf("checkNextWeek() = %d, %v, want week %d", week, ok, tt.wantWeek)
+			}
+		})
+	}
+}
+
+func TestClaimWeek(t *testing.T) { // A week can be claimed once, and only after the week before it
+	store := newTestStore(t, "Chelsea", "Arsenal")
+	season, err := store.Season()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.ClaimWeek(season, 2); !errors.Is(err, errWeekAlreadyPlayed) {
+		t.Errorf("claiming week 2 first: error = %v, want %v", err, errWeekAlreadyPlayed)
+	}
+	if err := store.ClaimWeek(season, 1); err != nil {
+		t.Fatalf("claiming week 1: %v", err)
+	}
+	if err := store.ClaimWeek(season, 1); !errors.Is(err, errWeekAlreadyPlayed) {
+		t.Errorf("claiming week 1 again: error = %v, want %v", err, errWeekAlreadyPlayed)
+	}
+
+	if season, err = store.Season(); err != nil || season.CurrentWeek != 1 || season.Finished {
+		t.Errorf("season = %+v, %v, want week 1 of 2", season, err)
+	}
+	if err := store.ClaimWeek(season, 2); err != nil {
+		t.Fatalf("claiming week 2: %v", err)
+	}
+	if season, err = store.Season(); err != nil || !season.Finished {
+		t.Errorf("season = %+v, %v, want finished", season, err)
+	}
+}
+
+func TestStartSeason(t *testing.T) { // A new season archives the current table, resets every team's stats and schedules a full season of fixtures
+	store := newTestStore(t, "Chelsea", "Arsenal", "Manchester City")
+	first := currentSeason(t, store)
+	if err := store.ClaimWeek(first, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := PlayWeekMatches(store, first.ID, 1, weekRand(1, 1)); err != nil {
+		t.Fatal(err)
+	}
+	before, err := store.Teams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := StartSeason(store, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID == first.ID || second.CurrentWeek != 0 || second.Weeks != seasonLength(3) {
+		t.Errorf("new season = %+v, want week 0 of %d", second, seasonLength(3))
+	}
+
+	seasons, err := store.Seasons()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seasons) != 2 || seasons[0].ID != second.ID || !seasons[1].Archived {
+		t.Errorf("seasons = %+v, want the new season first and the old one archived", seasons)
+	}
+
+	archived, err := store.ArchivedStandings(first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(archived, before) {
+		t.Errorf("archived standings = %+v, want %+v", archived, before)
+	}
+
+	teams, err := store.Teams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, team := range teams {
+		if team.Played != 0 || team.Points != 0 || team.GF != 0 || team.GA != 0 {
+			t.Errorf("%s starts the new season with %+v, want no stats", team.Name, team)
+		}
+	}
+
+	if matches, err := store.Matches(second.ID, 0); err != nil || len(matches) != 6 {
+		t.Errorf("new season has %d fixtures, %v, want 6", len(matches), err)
+	}
+}
+
+func playSeason(t *testing.T, teams []string, seed int64) LeagueStore { // playSeason seeds an in-memory league and plays its whole season week by week with the given seed
+	t.Helper()
+	store := newTestStore(t, teams...)
+	season := currentSeason(t, store)
+	for week := 1; week <= season.Weeks; week++ {
+		if _, err := simulateWeek(store, season, week, weekRand(seed, week)); err != nil {
+			t.Fatalf("week %d: %v", week, err)
+		}
+		season.CurrentWeek = week
+	}
+	return store
+}
+
+func TestPlaySeason(t *testing.T) { // A played season gives every team all its matches, a timeline adding up to each score and the same results for the same seed
+	tests := []struct {
+		name  string
+		teams []string
+	}{
+		{"even team count", []string{"Chelsea", "Arsenal", "Manchester City", "Liverpool"}},
+		{"odd team count", []string{"Chelsea", "Arsenal", "Manchester City", "Liverpool", "Everton"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := playSeason(t, tt.teams, 42)
+
+			season, err := store.Season()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !season.Archived || season.CurrentWeek != seasonLength(len(tt.teams)) {
+				t.Errorf("season = %+v, want every week played and the season archived", season)
+			}
+
+			teams, err := store.Teams()
+			if err != nil {
+				t.Fatal(err)
+			}
+			goalsFor, goalsAgainst := 0, 0
+			for _, team := range teams {
+				if want := 2 * (len(tt.teams) - 1); team.Played != want {
+					t.Errorf("%s played %d matches, want %d", team.Name, team.Played, want)
+				}
+				goalsFor += team.GF
+				goalsAgainst += team.GA
+			}
+			if goalsFor != goalsAgainst {
+				t.Errorf("goals for %d != goals against %d", goalsFor, goalsAgainst)
+			}
+
+			matches, err := store.Matches(season.ID, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			events, err := store.Events(season.ID, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			goals := make(map[[2]int]int) // Goal events of each match and team
+			for _, event := range events {
+				if event.Type == eventGoal {
+					goals[[2]int{event.MatchID, event.TeamID}]++
+				}
+			}
+			for _, match := range matches {
+				if !match.Played {
+					t.Fatalf("match %d was not played", match.ID)
+				}
+				if goals[[2]int{match.ID, match.HomeTeamID}] != match.HomeScore || goals[[2]int{match.ID, match.AwayTeamID}] != match.AwayScore {
+					t.Errorf("match %d ended %d-%d but its timeline has %d-%d", match.ID, match.HomeScore, match.AwayScore, goals[[2]int{match.ID, match.HomeTeamID}], goals[[2]int{match.ID, match.AwayTeamID}])
+				}
+			}
+
+			replayed, err := playSeason(t, tt.teams, 42).Matches(season.ID, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(replayed, matches) {
+				t.Error("the same seed gave different results")
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import ( // Import required packages:
+	"net/http" // For HTTP request handling
+)
+
+type server struct { // server holds what the HTTP handlers share, so each request reuses the same storage and database pool
+	storage Storage     // Storage of every league, shared by every request
+	locks   leagueLocks // Serializes changes to each league
+}
+
+func (srv *server) routes() { // routes registers the HTTP handlers for different routes on Front-end, each reporting errors and panics in its own format
+	http.HandleFunc("/", htmlHandler(indexHandler))
+	http.HandleFunc("/simulate", htmlHandler(srv.simulateHandler))
+	http.HandleFunc("/all", htmlHandler(srv.allLeagueHandler))
+	http.HandleFunc("/changeStrengths", jsonHandler(srv.changeStrengthsHandler))
+	http.HandleFunc("/teamStrengths", jsonHandler(srv.getTeamStrengthsHandler))
+	http.HandleFunc("/api/teams", jsonHandler(srv.teamsAPIHandler))
+	http.HandleFunc("/api/import", jsonHandler(srv.importHandler))
+	http.HandleFunc("/api/rules", jsonHandler(srv.rulesAPIHandler))
+	http.HandleFunc("/api/deductions", jsonHandler(srv.deductionsAPIHandler))
+	http.HandleFunc("/api/ratings", jsonHandler(srv.ratingsAPIHandler))
+	http.HandleFunc("/api/export/table", jsonHandler(srv.exportTableHandler))
+	http.HandleFunc("/api/export/matches", jsonHandler(srv.exportMatchesHandler))
+	http.HandleFunc("/api/season", jsonHandler(srv.seasonAPIHandler))
+	http.HandleFunc("/api/seasons", jsonHandler(srv.seasonsAPIHandler))
+	http.HandleFunc("/api/standings", jsonHandler(srv.standingsAPIHandler))
+	http.HandleFunc("/api/newSeason", jsonHandler(srv.newSeasonHandler))
+	http.HandleFunc("/api/table", jsonHandler(srv.tableAPIHandler))
+	http.HandleFunc("/api/matches", jsonHandler(srv.matchesAPIHandler))
+	http.HandleFunc("/api/events", jsonHandler(srv.eventsAPIHandler))
+	http.HandleFunc("/api/editMatch", jsonHandler(srv.editMatchHandler))
+	http.HandleFunc("/api/predictions", jsonHandler(srv.predictionsAPIHandler))
+	http.HandleFunc("/leagues", jsonHandler(srv.createLeagueHandler))
+}
@@ -0,0 +1,179 @@
+package main
+
+import ( // Import required packages:
+	"errors"    // For matching error values
+	"math/rand" // For the drawing of lots
+	"sort"      // For ranking teams
+)
+
+const ( // Tiebreakers that separate teams level on points, in the form stored in a league's rules
+	tiebreakGoalDifference   = "gd"         // Goal difference
+	tiebreakGoalsFor         = "gf"         // Goals scored
+	tiebreakHeadToHeadPoints = "h2h_points" // Points in the matches between the tied teams
+	tiebreakHeadToHeadGD     = "h2h_gd"     // Goal difference in the matches between the tied teams
+	tiebreakAwayGoals        = "away_goals" // Goals scored in away matches
+	tiebreakLots             = "lots"       // Drawing of lots, which always separates the teams
+)
+
+const rankPoints = "points" // First criterion of every ranking, ahead of the tiebreakers
+
+var knownTiebreakers = []string{tiebreakGoalDifference, tiebreakGoalsFor, tiebreakHeadToHeadPoints, tiebreakHeadToHeadGD, tiebreakAwayGoals, tiebreakLots} // Every known tiebreaker
+
+var defaultTiebreakers = []string{tiebreakGoalDifference, tiebreakGoalsFor, tiebreakHeadToHeadPoints, tiebreakHeadToHeadGD, tiebreakLots} // Tiebreakers of new leagues
+
+type seasonStanding struct { // seasonStanding holds a team's totals used for ranking, live or during one simulated season ending
+	Points int // Points earned
+	GD     int // Goal difference
+	GF     int // Goals for
+	AwayGF int // Goals for in away matches
+}
+
+type standingsTable struct { // standingsTable ranks a league table by points and then a chain of tiebreakers
+	teams   []seasonStanding // Totals of each team
+	index   map[int]int      // Map of team ID to position in teams
+	matches []Match          // Played matches, for the head-to-head tiebreakers
+	points  PointsRules      // Points earned for each head-to-head result
+	lots    []int            // Lot drawn by each team, higher ranks first; nil leaves teams that only lots could separate tied
+}
+
+func newStandingsTable(teams []Team, matches []Match, points PointsRules) *standingsTable { // newStandingsTable builds the table of teams from their stats and their season's played matches
+	table := &standingsTable{teams: make([]seasonStanding, len(teams)), index: make(map[int]int, len(teams)), points: points}
+	for i, team := range teams {
+		table.teams[i] = seasonStanding{Points: team.Points, GD: team.GD, GF: team.GF}
+		table.index[team.ID] = i
+	}
+	for _, match := range matches {
+		if !match.Played {
+			continue // Fixtures count once they are played
+		}
+		table.matches = append(table.matches, match)
+		if away, ok := table.index[match.AwayTeamID]; ok {
+			table.teams[away].AwayGF += match.AwayScore
+		}
+	}
+	return table
+}
+
+func (t *standingsTable) rank(tiebreakers []string) [][]int { // rank returns the team positions in table order, grouped with the teams no tiebreaker could separate
+	group := make([]int, len(t.teams))
+	for i := range group {
+		group[i] = i
+	}
+	return t.split(group, append([]string{rankPoints}, tiebreakers...))
+}
+
+func (t *standingsTable) split(group []int, chain []string) [][]int { // split orders a group of tied teams by the first criterion of chain, then splits each run of equal teams with the rest
+	if len(group) < 2 || len(chain) == 0 {
+		return [][]int{group}
+	}
+
+	keys := t.keys(group, chain[0]) // Value of the criterion for each team, higher ranks first
+	sort.SliceStable(group, func(a, b int) bool {
+		return keys[group[a]] > keys[group[b]]
+	})
+
+	var groups [][]int
+	for start := 0; start < len(group); { // Walk runs of teams with the same value
+		end := start + 1
+		for end < len(group) && keys[group[end]] == keys[group[start]] {
+			end++
+		}
+		groups = append(groups, t.split(group[start:end:end], chain[1:])...)
+		start = end
+	}
+	return groups
+}
+
+func (t *standingsTable) keys(group []int, criterion string) map[int]int { // keys returns the value of criterion for each team of group
+	keys := make(map[int]int, len(group))
+	switch criterion {
+	case tiebreakHeadToHeadPoints, tiebreakHeadToHeadGD:
+		inGroup := make(map[int]bool, len(group))
+		for _, i := range group {
+			inGroup[i] = true
+		}
+		for _, match := range t.matches { // Mini-table of the matches between the teams of the group
+			home, okHome := t.index[match.HomeTeamID]
+			away, okAway := t.index[match.AwayTeamID]
+			if !okHome || !okAway || !inGroup[home] || !inGroup[away] {
+				continue
+			}
+			if criterion == tiebreakHeadToHeadGD {
+				keys[home] += match.HomeScore - match.AwayScore
+				keys[away] += match.AwayScore - match.HomeScore
+				continue
+			}
+			keys[home] += t.points.matchPoints(match.HomeScore, match.AwayScore)
+			keys[away] += t.points.matchPoints(match.AwayScore, match.HomeScore)
+		}
+	default:
+		for _, i := range group {
+			switch criterion {
+			case rankPoints:
+				keys[i] = t.teams[i].Points
+			case tiebreakGoalDifference:
+				keys[i] = t.teams[i].GD
+			case tiebreakGoalsFor:
+				keys[i] = t.teams[i].GF
+			case tiebreakAwayGoals:
+				keys[i] = t.teams[i].AwayGF
+			case tiebreakLots:
+				if t.lots != nil {
+					keys[i] = t.lots[i]
+				}
+			}
+		}
+	}
+	return keys
+}
+
+func rankTeams(teams []Team, matches []Match, rules Rules, lotSeed int64) []Team { // rankTeams returns teams in table order; lots are drawn from lotSeed, so a season's order never changes between requests
+	table := newStandingsTable(teams, matches, rules.Points)
+	if len(table.matches) > 0 {
+		table.lots = rand.New(rand.NewSource(lotSeed)).Perm(len(teams)) // Before the first result the table stays in ID order
+	}
+
+	ranked := make([]Team, 0, len(teams))
+	for _, group := range table.rank(rules.Tiebreakers) {
+		for _, i := range group {
+			ranked = append(ranked, teams[i])
+		}
+	}
+	return ranked
+}
+
+func rankedTable(store LeagueStore, season Season, teams []Team) ([]Team, error) { // rankedTable orders a season's table with the league's rules and the season's played matches
+	rules, err := store.Rules()
+	if err != nil {
+		return nil, err
+	}
+	matches, err := store.Matches(season.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return rankTeams(teams, matches, rules, season.FixtureSeed), nil
+}
+
+func leagueTable(store LeagueStore) ([]Team, error) { // leagueTable returns the current league table in table order
+	season, err := store.Season()
+	if err != nil && !errors.Is(err, errNoSeason) {
+		return nil, err
+	}
+	teams, err := store.Teams()
+	if err != nil {
+		return nil, err
+	}
+	return rankedTable(store, season, teams) // Without a season there are no matches for the head-to-head tiebreakers
+}
+
+func archivedTable(store LeagueStore, seasonID int) ([]Team, error) { // archivedTable returns the final standings of an archived season in table order
+	teams, err := store.ArchivedStandings(seasonID)
+	if err != nil {
+		return nil, err
+	}
+	season, err := findSeason(store, seasonID) // errSeasonNotFound for seasons of other leagues or that never existed
+	if err != nil {
+		return nil, err
+	}
+	return rankedTable(store, season, teams)
+}
@@ -0,0 +1,127 @@
+package main
+
+import ( // Import required packages:
+	"errors"  // For matching error values
+	"reflect" // For comparing rankings
+	"testing" // For the test framework
+)
+
+func TestStandingsTableRank(t *testing.T) { // Teams are ranked by points, then by each tiebreaker in turn, head-to-head counting only the matches between the tied teams
+	played := func(week, home, away, homeScore, awayScore int) Match { // A played match between two teams
+		return Match{HomeTeamID: home, AwayTeamID: away, HomeScore: homeScore, AwayScore: awayScore, Week: week, Played: true}
+	}
+
+	tests := []struct {
+		name        string
+		teams       []Team
+		matches     []Match
+		tiebreakers []string
+		want        [][]int // Positions in teams, in table order, grouped when no tiebreaker separates them
+	}{
+		{
+			name:        "points first",
+			teams:       []Team{{ID: 1, Points: 3}, {ID: 2, Points: 6}, {ID: 3, Points: 0, GD: 9}},
+			tiebreakers: defaultTiebreakers,
+			want:        [][]int{{1}, {0}, {2}},
+		},
+		{
+			name:        "goal difference before head-to-head",
+			teams:       []Team{{ID: 1, Points: 3, GD: 0}, {ID: 2, Points: 3, GD: 2}},
+			matches:     []Match{played(1, 1, 2, 1, 0), played(2, 2, 1, 3, 0)},
+			tiebreakers: []string{tiebreakGoalDifference, tiebreakHeadToHeadPoints},
+			want:        [][]int{{1}, {0}},
+		},
+		{
+			name:        "head-to-head points before goal difference",
+			teams:       []Team{{ID: 1, Points: 4, GD: 5}, {ID: 2, Points: 4, GD: 1}, {ID: 3, Points: 0}},
+			matches:     []Match{played(1, 1, 3, 6, 0), played(2, 2, 1, 1, 0), played(3, 3, 2, 0, 0)},
+			tiebreakers: []string{tiebreakHeadToHeadPoints, tiebreakGoalDifference},
+			want:        [][]int{{1}, {0}, {2}},
+		},
+		{
+			name:  "head-to-head goal difference in a three-way tie",
+			teams: []Team{{ID: 1, Points: 3}, {ID: 2, Points: 3}, {ID: 3, Points: 3}},
+			matches: []Match{
+				played(1, 1, 2, 3, 0), // Every team wins once, so only goal difference between them separates them
+				played(2, 2, 3, 1, 0),
+				played(3, 3, 1, 1, 0),
+			},
+			tiebreakers: []string{tiebreakHeadToHeadPoints, tiebreakHeadToHeadGD},
+			want:        [][]int{{0}, {2}, {1}},
+		},
+		{
+			name:        "head-to-head ignores matches against other teams",
+			teams:       []Team{{ID: 1, Points: 4, GF: 6}, {ID: 2, Points: 4, GF: 1}, {ID: 3, Points: 0}},
+			matches:     []Match{played(1, 1, 3, 5, 0), played(2, 2, 1, 1, 1), played(3, 3, 2, 0, 0)},
+			tiebreakers: []string{tiebreakHeadToHeadPoints, tiebreakHeadToHeadGD, tiebreakGoalsFor},
+			want:        [][]int{{0}, {1}, {2}},
+		},
+		{
+			name:        "head-to-head splits the teams it separates and passes the rest on",
+			teams:       []Team{{ID: 1, Points: 3, GD: 1}, {ID: 2, Points: 3, GD: 2}, {ID: 3, Points: 3, GD: 3}},
+			matches:     []Match{played(1, 1, 2, 0, 0), played(2, 2, 3, 1, 0), played(3, 3, 1, 0, 1)},
+			tiebreakers: []string{tiebreakHeadToHeadPoints, tiebreakGoalDifference},
+			want:        [][]int{{1}, {0}, {2}},
+		},
+		{
+			name:        "away goals",
+			teams:       []Team{{ID: 1, Points: 1}, {ID: 2, Points: 1}},
+			matches:     []Match{played(1, 1, 2, 2, 2), played(2, 2, 1, 0, 0)},
+			tiebreakers: []string{tiebreakAwayGoals},
+			want:        [][]int{{1}, {0}},
+		},
+		{
+			name:        "unplayed fixtures do not count",
+			teams:       []Team{{ID: 1, Points: 3}, {ID: 2, Points: 3}},
+			matches:     []Match{{HomeTeamID: 1, AwayTeamID: 2, HomeScore: 5, Week: 1}},
+			tiebreakers: []string{tiebreakHeadToHeadPoints, tiebreakHeadToHeadGD},
+			want:        [][]int{{0, 1}},
+		},
+		{
+			name:        "lots are not drawn without a seed",
+			teams:       []Team{{ID: 1, Points: 1, GF: 1}, {ID: 2, Points: 1, GF: 1}},
+			matches:     []Match{played(1, 1, 2, 1, 1)},
+			tiebreakers: defaultTiebreakers,
+			want:        [][]int{{0, 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newStandingsTable(tt.teams, tt.matches, defaultRules().Points).rank(tt.tiebreakers)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rank() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRules(t *testing.T) { // Tiebreakers must be known, listed once and end with the drawing of lots if it is used
+	tests := []struct {
+		name        string
+		tiebreakers []string
+		want        []string // Accepted chain, or nil when the rules are rejected
+	}{
+		{"default chain", defaultTiebreakers, defaultTiebreakers},
+		{"points alone", nil, []string{}},
+		{"away goals", []string{tiebreakAwayGoals, tiebreakGoalDifference}, []string{tiebreakAwayGoals, tiebreakGoalDifference}},
+		{"unknown tiebreaker", []string{"fair_play"}, nil},
+		{"repeated tiebreaker", []string{tiebreakGoalsFor, tiebreakGoalsFor}, nil},
+		{"tiebreaker after lots", []string{tiebreakLots, tiebreakGoalsFor}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := checkRules(Rules{Tiebreakers: tt.tiebreakers})
+			if tt.want == nil {
+				if !errors.Is(err, errInvalidRules) {
+					t.Errorf("checkRules() error = %v, want %v", err, errInvalidRules)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(rules.Tiebreakers, tt.want) {
+				t.Errorf("checkRules() = %v, %v, want %v", rules.Tiebreakers, err, tt.want)
+			}
+		})
+	}
+}
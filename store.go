@@ -0,0 +1,65 @@
+package main
+
+import ( // Import required packages:
+	"errors" // For creating error values
+	"fmt"    // For formatted errors
+)
+
+var ( // Errors returned by every LeagueStore
+	errNoSeason     = errors.New("no season has been started") // Returned by Season when the league has no season yet
+	errTeamNotFound = errors.New("team not found")             // Returned by Team for an unknown team ID
+)
+
+type LeagueStore interface { // LeagueStore holds a league's teams, matches and seasons, so the simulation does not depend on where they are stored
+	Teams() ([]Team, error)                                            // Every team with its current stats, in ID order (removed teams are left out)
+	Team(id int) (Team, error)                                         // One team with its current stats, or errTeamNotFound
+	AddTeam(team Team) (Team, error)                                   // Adds a team with its name, short code and match engine parameters, empty stats and the starting rating of its strength
+	EditTeam(team Team) error                                          // Saves a team's name, short code, strength, attack, defense, home advantage and rating
+	RemoveTeam(id int) error                                           // Removes a team from the league, keeping its name on earlier seasons' matches
+	UpdateTeam(team Team) error                                        // Saves a team's stats, strength and rating
+	ResetTeamStats() error                                             // Clears every team's stats, keeping names, strengths and ratings
+	Matches(seasonID, week int) ([]Match, error)                       // A season's matches of a week (or of every week if week is 0) with team names, in week then ID order
+	Match(seasonID, matchID int) (Match, error)                        // One match of a season with team names, or errMatchNotFound
+	AddFixtures(seasonID int, fixtures []Fixture) error                // Stores fixtures as unplayed matches of a season
+	RemoveFixtures(seasonID int) error                                 // Deletes a season's unplayed matches, so its fixtures can be generated again
+	SaveMatch(match Match) error                                       // Records a match's score and marks it as played
+	Season() (Season, error)                                           // The current (latest) season, or errNoSeason
+	Seasons() ([]Season, error)                                        // Every season, newest first
+	AddSeason(weeks int, fixtureSeed int64) (Season, error)            // Starts a new season of the given length at week 0, without fixtures
+	SetSchedule(seasonID, weeks int, scheduled bool) error             // Sets a season's length and whether its fixtures have been generated
+	ClaimWeek(season Season, week int) error                           // Advances a season to week, or errWeekAlreadyPlayed if the previous week is no longer the current one
+	ArchiveSeason(seasonID int) error                                  // Stores the current table as a season's final standings (once per season)
+	ArchivedStandings(seasonID int) ([]Team, error)                    // Final standings stored for an archived season
+	Rules() (Rules, error)                                             // The league's competition rules
+	SetRules(rules Rules) error                                        // Replaces the league's competition rules
+	Deductions(seasonID int) ([]Deduction, error)                      // Point deductions of a season with team names, in ID order
+	AddDeduction(seasonID int, deduction Deduction) (Deduction, error) // Records a point deduction; the team's points are changed separately
+	RemoveDeduction(id int) error                                      // Deletes a point deduction; the team's points are changed separately
+	Ratings(seasonID int) ([]Rating, error)                            // Every team's rating after each week of a season with team names, in week then team ID order
+	SaveRatings(seasonID, week int, ratings []Rating) error            // Replaces the ratings stored for a week of a season
+	Events(seasonID, week int) ([]MatchEvent, error)                   // Timeline events of a season's matches of a week (or of every week if week is 0) with team names, in match then minute order
+	SaveEvents(matchID int, events []MatchEvent) error                 // Replaces the timeline of a match
+	Update(fn func(LeagueStore) error) error                           // Runs fn atomically: every change made through the given store is kept, or none if fn fails
+}
+
+type Storage interface { // Storage holds every league and hands out a LeagueStore scoped to one of them, so leagues never see each other's data
+	CreateLeague(code, name string, seed func(LeagueStore) error) (League, error) // Adds a league with the given unique code and fills it with seed, atomically: no league is left behind if seed fails
+	FindLeague(code string) (League, error)                                       // The league with the given code, or errLeagueNotFound
+	League(leagueID int) LeagueStore                                              // Teams, matches and seasons of one league
+	Close() error                                                                 // Releases the storage's resources
+}
+
+func openStore(backend, path string) (Storage, error) { // openStore returns the storage selected with the -store flag; the SQLite one is kept in the database file at path
+	switch backend {
+	case "sqlite":
+		db, err := SetupDatabase(path) // Initialize the database
+		if err != nil {
+			return nil, err
+		}
+		return newSQLiteStorage(db), nil
+	case "memory":
+		return newMemoryStorage(), nil
+	default:
+		return nil, fmt.Errorf("unknown store %q (use sqlite or memory)", backend)
+	}
+}
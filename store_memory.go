@@ -0,0 +1,480 @@
+package main
+
+import ( // Import required packages:
+	"fmt"    // For formatted errors
+	"slices" // For copying the tiebreaker list
+	"sort"   // For ordering matches and seasons
+	"sync"   // For guarding the leagues against concurrent requests
+)
+
+type memoryStorage struct { // memoryStorage is a Storage held in memory, for simulations that should not touch league.db
+	mu      sync.Mutex           // Guards leagues and stores
+	leagues []League             // Leagues in ID order
+	stores  map[int]*memoryStore // Store of each league, by league ID
+}
+
+func newMemoryStorage() *memoryStorage { // newMemoryStorage returns an in-memory storage holding only the empty default league
+	return &memoryStorage{
+		leagues: []League{{ID: defaultLeagueID, Code: defaultLeagueCode, Name: defaultLeagueName}},
+		stores:  make(map[int]*memoryStore),
+	}
+}
+
+func (s *memoryStorage) CreateLeague(code, name string, seed func(LeagueStore) error) (League, error) { // CreateLeague seeds a new league and adds it only if seed succeeds
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, league := range s.leagues {
+		if league.Code == code {
+			return League{}, fmt.Errorf("league code %q is already used", code) // Like the UNIQUE constraint of the SQLite store
+		}
+	}
+	league := League{ID: len(s.leagues) + 1, Code: code, Name: name}
+	store := newMemoryStore()
+	if err := store.Update(seed); err != nil {
+		return League{}, err // The league is never added, so nothing can see it
+	}
+	s.leagues = append(s.leagues, league)
+	s.stores[league.ID] = store
+	return league, nil
+}
+
+func (s *memoryStorage) FindLeague(code string) (League, error) { // FindLeague returns the league with the given code
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, league := range s.leagues {
+		if league.Code == code {
+			return league, nil
+		}
+	}
+	return League{}, errLeagueNotFound
+}
+
+func (s *memoryStorage) League(leagueID int) LeagueStore { // League returns the store of one league, creating it on first use
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store, ok := s.stores[leagueID]
+	if !ok {
+		store = newMemoryStore()
+		s.stores[leagueID] = store
+	}
+	return store
+}
+
+func (s *memoryStorage) Close() error { // Close does nothing; the leagues live as long as the process
+	return nil
+}
+
+type memoryData struct { // memoryData is the league held by a memoryStore
+	teams      []Team            // Teams in ID order, including removed ones so earlier matches keep their names
+	removed    map[int]bool      // IDs of removed teams
+	matches    []memoryMatch     // Matches in ID order, without team names
+	seasons    []Season          // Seasons in ID order
+	standings  map[int][]Team    // Archived final standings by season ID
+	rules      Rules             // Competition rules
+	deductions []memoryDeduction // Point deductions in ID order, including removed ones so IDs are not reused
+	ratings    []memoryRating    // Rating history of every season
+	events     []MatchEvent      // Timeline events of every match, without team names
+}
+
+type memoryStore struct { // memoryStore is a LeagueStore held in memory, for simulations that should not touch league.db
+	mu   *sync.Mutex // Guards data; shared with the store handed to Update
+	data *memoryData // League data
+	inTx bool        // Whether the store is used inside Update, where mu is already held
+}
+
+func newMemoryStore() *memoryStore { // newMemoryStore returns an empty in-memory league
+	return &memoryStore{mu: &sync.Mutex{}, data: &memoryData{removed: make(map[int]bool), standings: make(map[int][]Team), rules: defaultRules()}}
+}
+
+func (s *memoryStore) lock() func() { // lock acquires the store's mutex unless Update already holds it, returning the matching unlock
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *memoryStore) Teams() ([]Team, error) { // Teams returns a copy of every team with its current stats
+	defer s.lock()()
+	return s.activeTeams(), nil
+}
+
+func (s *memoryStore) activeTeams() []Team { // activeTeams returns a copy of the teams that have not been removed, with the lock held
+	teams := []Team{}
+	for _, team := range s.data.teams {
+		if !s.data.removed[team.ID] {
+			teams = append(teams, team)
+		}
+	}
+	return teams
+}
+
+func (s *memoryStore) Team(id int) (Team, error) { // Team returns one team with its current stats
+	defer s.lock()()
+	for _, team := range s.data.teams {
+		if team.ID == id && !s.data.removed[id] {
+			return team, nil
+		}
+	}
+	return Team{}, errTeamNotFound
+}
+
+func (s *memoryStore) AddTeam(team Team) (Team, error) { // AddTeam adds a team with empty stats
+	defer s.lock()()
+	team = Team{ID: len(s.data.teams) + 1, Name: team.Name, ShortCode: team.ShortCode, Strength: team.Strength, Attack: team.Attack, Defense: team.Defense, // Team IDs are their position in the slice, starting at 1
+		HomeAdvantage: team.HomeAdvantage, Elo: eloForStrength(team.Strength)}
+	s.data.teams = append(s.data.teams, team)
+	return team, nil
+}
+
+func (s *memoryStore) EditTeam(team Team) error { // EditTeam saves a team's name, short code, strength, attack, defense, home advantage and rating
+	defer s.lock()()
+	for i, stored := range s.data.teams {
+		if stored.ID == team.ID && !s.data.removed[team.ID] {
+			stored.Name, stored.ShortCode, stored.Strength, stored.Elo = team.Name, team.ShortCode, team.Strength, team.Elo
+			stored.Attack, stored.Defense, stored.HomeAdvantage = team.Attack, team.Defense, team.HomeAdvantage
+			s.data.teams[i] = stored
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) RemoveTeam(id int) error { // RemoveTeam marks a team as removed, keeping it so earlier matches still show its name
+	defer s.lock()()
+	s.data.removed[id] = true
+	return nil
+}
+
+func (s *memoryStore) UpdateTeam(team Team) error { // UpdateTeam saves a team's stats, strength and rating
+	defer s.lock()()
+	for i := range s.data.teams {
+		if s.data.teams[i].ID == team.ID {
+			stored := s.data.teams[i] // Only stats, strength and rating are updated
+			team.Name, team.Attack, team.Defense, team.HomeAdvantage = stored.Name, stored.Attack, stored.Defense, stored.HomeAdvantage
+			s.data.teams[i] = team
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) ResetTeamStats() error { // ResetTeamStats clears every team's stats, keeping names, short codes, strengths and ratings
+	defer s.lock()()
+	for i, team := range s.data.teams {
+		s.data.teams[i] = Team{ID: team.ID, Name: team.Name, ShortCode: team.ShortCode, Strength: team.Strength, Attack: team.Attack, Defense: team.Defense,
+			HomeAdvantage: team.HomeAdvantage, Elo: team.Elo}
+	}
+	return nil
+}
+
+func (s *memoryStore) teamName(id int) string { // teamName returns the name of a team, with the lock held
+	for _, team := range s.data.teams {
+		if team.ID == id {
+			return team.Name
+		}
+	}
+	return ""
+}
+
+func (s *memoryStore) Matches(seasonID, week int) ([]Match, error) { // Matches returns a season's matches of a week (or of every week if week is 0) with team names
+	defer s.lock()()
+	matches := []Match{}
+	for _, match := range s.data.matches {
+		if match.seasonID != seasonID || match.removed || (week > 0 && match.Week != week) {
+			continue
+		}
+		match.HomeTeam = s.teamName(match.HomeTeamID)
+		match.AwayTeam = s.teamName(match.AwayTeamID)
+		matches = append(matches, match.Match)
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Week < matches[j].Week }) // Week then ID order, like the SQLite store
+	return matches, nil
+}
+
+func (s *memoryStore) Match(seasonID, matchID int) (Match, error) { // Match returns one match of a season with team names
+	defer s.lock()()
+	for _, match := range s.data.matches {
+		if match.ID == matchID && match.seasonID == seasonID && !match.removed {
+			match.HomeTeam = s.teamName(match.HomeTeamID)
+			match.AwayTeam = s.teamName(match.AwayTeamID)
+			return match.Match, nil
+		}
+	}
+	return Match{}, errMatchNotFound
+}
+
+func (s *memoryStore) AddFixtures(seasonID int, fixtures []Fixture) error { // AddFixtures stores fixtures as unplayed matches of a season
+	defer s.lock()()
+	for _, fixture := range fixtures {
+		match := Match{ID: len(s.data.matches) + 1, HomeTeamID: fixture.HomeTeamID, AwayTeamID: fixture.AwayTeamID, Week: fixture.Week}
+		s.data.matches = append(s.data.matches, memoryMatch{Match: match, seasonID: seasonID})
+	}
+	return nil
+}
+
+func (s *memoryStore) RemoveFixtures(seasonID int) error { // RemoveFixtures marks a season's unplayed matches as removed, keeping them so match IDs are not reused
+	defer s.lock()()
+	for i, match := range s.data.matches {
+		if match.seasonID == seasonID && !match.Played {
+			s.data.matches[i].removed = true
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) SaveMatch(match Match) error { // SaveMatch records a match's score and marks it as played
+	defer s.lock()()
+	if match.ID < 1 || match.ID > len(s.data.matches) || s.data.matches[match.ID-1].removed {
+		return nil // Like an UPDATE matching no rows
+	}
+	stored := &s.data.matches[match.ID-1] // Match IDs are their position in the slice, starting at 1
+	stored.HomeScore, stored.AwayScore, stored.Played = match.HomeScore, match.AwayScore, true
+	return nil
+}
+
+func (s *memoryStore) Season() (Season, error) { // Season returns the state of the current season
+	defer s.lock()()
+	if len(s.data.seasons) == 0 {
+		return Season{}, errNoSeason
+	}
+	return withFinished(s.data.seasons[len(s.data.seasons)-1]), nil
+}
+
+func (s *memoryStore) Seasons() ([]Season, error) { // Seasons returns every season, newest first
+	defer s.lock()()
+	seasons := []Season{}
+	for i := len(s.data.seasons) - 1; i >= 0; i-- {
+		seasons = append(seasons, withFinished(s.data.seasons[i]))
+	}
+	return seasons, nil
+}
+
+func (s *memoryStore) AddSeason(weeks int, fixtureSeed int64) (Season, error) { // AddSeason starts a new season at week 0, before its fixtures are generated
+	defer s.lock()()
+	season := Season{ID: len(s.data.seasons) + 1, Weeks: weeks, FixtureSeed: fixtureSeed}
+	s.data.seasons = append(s.data.seasons, season)
+	return withFinished(season), nil
+}
+
+func (s *memoryStore) SetSchedule(seasonID, weeks int, scheduled bool) error { // SetSchedule sets a season's length and whether its fixtures have been generated
+	defer s.lock()()
+	if stored := s.season(seasonID); stored != nil {
+		stored.Weeks, stored.Scheduled = weeks, scheduled
+	}
+	return nil
+}
+
+func (s *memoryStore) ClaimWeek(season Season, week int) error { // ClaimWeek advances the season to the given week, failing if the previous week is no longer the current one
+	defer s.lock()()
+	stored := s.season(season.ID)
+	if stored == nil || stored.CurrentWeek != week-1 {
+		return errWeekAlreadyPlayed // Another request has already played this week
+	}
+	stored.CurrentWeek = week
+	return nil
+}
+
+func (s *memoryStore) ArchiveSeason(seasonID int) error { // ArchiveSeason stores a copy of the league table as the season's final standings
+	defer s.lock()()
+	stored := s.season(seasonID)
+	if stored == nil || stored.Archived {
+		return nil // Unknown or already archived
+	}
+	stored.Archived = true
+	s.data.standings[seasonID] = s.activeTeams()
+	return nil
+}
+
+func (s *memoryStore) ArchivedStandings(seasonID int) ([]Team, error) { // ArchivedStandings returns the final standings stored for an archived season
+	defer s.lock()()
+	return append([]Team{}, s.data.standings[seasonID]...), nil
+}
+
+func (s *memoryStore) Rules() (Rules, error) { // Rules returns a copy of the league's competition rules
+	defer s.lock()()
+	return Rules{Tiebreakers: slices.Clone(s.data.rules.Tiebreakers), Points: s.data.rules.Points, EloStrength: s.data.rules.EloStrength}, nil
+}
+
+func (s *memoryStore) SetRules(rules Rules) error { // SetRules replaces the league's competition rules
+	defer s.lock()()
+	s.data.rules = Rules{Tiebreakers: slices.Clone(rules.Tiebreakers), Points: rules.Points, EloStrength: rules.EloStrength}
+	return nil
+}
+
+func (s *memoryStore) Deductions(seasonID int) ([]Deduction, error) { // Deductions returns a season's point deductions with team names
+	defer s.lock()()
+	deductions := []Deduction{}
+	for _, deduction := range s.data.deductions {
+		if deduction.seasonID == seasonID && !deduction.removed {
+			deduction.Team = s.teamName(deduction.TeamID)
+			deductions = append(deductions, deduction.Deduction)
+		}
+	}
+	return deductions, nil
+}
+
+func (s *memoryStore) AddDeduction(seasonID int, deduction Deduction) (Deduction, error) { // AddDeduction records a point deduction
+	defer s.lock()()
+	deduction.ID = len(s.data.deductions) + 1
+	s.data.deductions = append(s.data.deductions, memoryDeduction{Deduction: deduction, seasonID: seasonID})
+	return deduction, nil
+}
+
+func (s *memoryStore) RemoveDeduction(id int) error { // RemoveDeduction marks a point deduction as removed
+	defer s.lock()()
+	if id >= 1 && id <= len(s.data.deductions) {
+		s.data.deductions[id-1].removed = true
+	}
+	return nil
+}
+
+func (s *memoryStore) Ratings(seasonID int) ([]Rating, error) { // Ratings returns a season's rating history with team names
+	defer s.lock()()
+	ratings := []Rating{}
+	for _, rating := range s.data.ratings {
+		if rating.seasonID == seasonID {
+			rating.Team = s.teamName(rating.TeamID)
+			ratings = append(ratings, rating.Rating)
+		}
+	}
+	sort.SliceStable(ratings, func(i, j int) bool { // Week then team ID order, like the SQLite store
+		if ratings[i].Week != ratings[j].Week {
+			return ratings[i].Week < ratings[j].Week
+		}
+		return ratings[i].TeamID < ratings[j].TeamID
+	})
+	return ratings, nil
+}
+
+func (s *memoryStore) SaveRatings(seasonID, week int, ratings []Rating) error { // SaveRatings replaces the ratings stored for a week of a season
+	defer s.lock()()
+	kept := s.data.ratings[:0:0]
+	for _, rating := range s.data.ratings {
+		if rating.seasonID != seasonID || rating.Week != week {
+			kept = append(kept, rating)
+		}
+	}
+	for _, rating := range ratings {
+		rating.Week = week
+		kept = append(kept, memoryRating{Rating: rating, seasonID: seasonID})
+	}
+	s.data.ratings = kept
+	return nil
+}
+
+func (s *memoryStore) Events(seasonID, week int) ([]MatchEvent, error) { // Events returns the timeline events of a season's matches of a week (or of every week if week is 0) with team names
+	defer s.lock()()
+	matches := make(map[int]Match) // Matches the events may belong to, by ID
+	for _, match := range s.data.matches {
+		if match.seasonID == seasonID && (week == 0 || match.Week == week) {
+			matches[match.ID] = match.Match
+		}
+	}
+
+	events := []MatchEvent{}
+	for _, event := range s.data.events {
+		if _, ok := matches[event.MatchID]; ok {
+			event.Team = s.teamName(event.TeamID)
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { // Week, match ID then minute order, like the SQLite store
+		a, b := matches[events[i].MatchID], matches[events[j].MatchID]
+		if a.Week != b.Week {
+			return a.Week < b.Week
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return events[i].Minute < events[j].Minute
+	})
+	return events, nil
+}
+
+func (s *memoryStore) SaveEvents(matchID int, events []MatchEvent) error { // SaveEvents replaces the timeline of a match
+	defer s.lock()()
+	kept := s.data.events[:0:0]
+	for _, event := range s.data.events {
+		if event.MatchID != matchID {
+			kept = append(kept, event)
+		}
+	}
+	for _, event := range events {
+		event.MatchID, event.Team = matchID, ""
+		kept = append(kept, event)
+	}
+	s.data.events = kept
+	return nil
+}
+
+func (s *memoryStore) season(id int) *Season { // season returns the stored season with the given ID, with the lock held
+	if id < 1 || id > len(s.data.seasons) {
+		return nil
+	}
+	return &s.data.seasons[id-1] // Season IDs are their position in the slice, starting at 1
+}
+
+func (s *memoryStore) Update(fn func(LeagueStore) error) error { // Update runs fn with the lock held, restoring the previous data if it fails or panics
+	if s.inTx {
+		return fn(s) // Already inside Update
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.data.clone()
+	committed := false
+	defer func() {
+		if !committed {
+			*s.data = *snapshot // Discard every change made by fn
+		}
+	}()
+
+	if err := fn(&memoryStore{mu: s.mu, data: s.data, inTx: true}); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func (d *memoryData) clone() *memoryData { // clone returns a copy of the league data that shares nothing with d
+	removed := make(map[int]bool, len(d.removed))
+	for id := range d.removed {
+		removed[id] = true
+	}
+	standings := make(map[int][]Team, len(d.standings))
+	for id, teams := range d.standings {
+		standings[id] = append([]Team{}, teams...)
+	}
+	return &memoryData{
+		teams:      append([]Team{}, d.teams...),
+		removed:    removed,
+		matches:    append([]memoryMatch{}, d.matches...),
+		seasons:    append([]Season{}, d.seasons...),
+		standings:  standings,
+		rules:      Rules{Tiebreakers: slices.Clone(d.rules.Tiebreakers), Points: d.rules.Points, EloStrength: d.rules.EloStrength},
+		deductions: append([]memoryDeduction{}, d.deductions...),
+		ratings:    append([]memoryRating{}, d.ratings...),
+		events:     append([]MatchEvent{}, d.events...),
+	}
+}
+
+type memoryMatch struct { // memoryMatch is a stored match with the season it belongs to
+	Match
+	seasonID int  // Season the match belongs to
+	removed  bool // Whether the fixture was removed before it was played
+}
+
+type memoryRating struct { // memoryRating is a stored rating with the season it belongs to
+	Rating
+	seasonID int // Season the rating belongs to
+}
+
+type memoryDeduction struct { // memoryDeduction is a stored point deduction with the season it belongs to
+	Deduction
+	seasonID int  // Season the deduction belongs to
+	removed  bool // Whether the deduction has been cancelled
+}
+
+func withFinished(season Season) Season { // withFinished sets whether every week of a season has been played
+	season.Finished = season.CurrentWeek >= season.Weeks
+	return season
+}
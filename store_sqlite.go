@@ -0,0 +1,483 @@
+package main
+
+import ( // Import required packages:
+	"database/sql" // For database operations
+	"errors"       // For matching error values
+	"strings"      // For storing the tiebreaker list in one column
+	"time"         // For the connection pool's idle timeout
+)
+
+const defaultDatabasePath = "league.db" // Database file used unless the -db flag names another
+
+// databaseDSN opens the database file at path in WAL mode, so reads do not wait for a simulation being written, and
+// waits up to 5 seconds for a busy database instead of failing with "database is locked". Transactions take the
+// write lock when they begin, so two transactions cannot deadlock upgrading from a read lock.
+func databaseDSN(path string) string {
+	return "file:" + path + "?mode=rwc&_loc=auto&_txlock=immediate&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)"
+}
+
+const (
+	maxOpenConns    = 8               // Most connections open at once; WAL allows many readers but only one writer
+	connMaxIdleTime = 5 * time.Minute // How long an unused connection stays open
+)
+
+type sqlExecutor interface { // sqlExecutor is satisfied by both *sql.DB and *sql.Tx, so table updates can run inside a transaction
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+type sqliteStorage struct { // sqliteStorage is the Storage kept in the league.db SQLite database
+	db *sql.DB // Database handle shared by every league's store
+}
+
+func newSQLiteStorage(db *sql.DB) *sqliteStorage { // newSQLiteStorage returns the storage using the given database handle, which it closes on Close
+	return &sqliteStorage{db: db}
+}
+
+func (s *sqliteStorage) CreateLeague(code, name string, seed func(LeagueStore) error) (League, error) { // CreateLeague inserts a league and seeds it in one transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return League{}, err
+	}
+	defer tx.Rollback() // Roll back unless the transaction is committed
+
+	result, err := tx.Exec("INSERT INTO leagues (code, name) VALUES (?, ?)", code, name)
+	if err != nil {
+		return League{}, err
+	}
+	leagueID, err := result.LastInsertId()
+	if err != nil {
+		return League{}, err
+	}
+	if err := seed(&sqliteStore{db: tx, leagueID: int(leagueID)}); err != nil { // Joins this transaction, like a store handed out by Update
+		return League{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return League{}, err
+	}
+	return League{ID: int(leagueID), Code: code, Name: name}, nil
+}
+
+func (s *sqliteStorage) FindLeague(code string) (League, error) { // FindLeague returns the league with the given code
+	var league League
+	err := s.db.QueryRow("SELECT id, code, COALESCE(name, '') FROM leagues WHERE code = ?", code).Scan(&league.ID, &league.Code, &league.Name) // Query to retrieve the league
+	if errors.Is(err, sql.ErrNoRows) {
+		return League{}, errLeagueNotFound
+	}
+	return league, err
+}
+
+func (s *sqliteStorage) League(leagueID int) LeagueStore { // League returns the store of one league's rows
+	return &sqliteStore{db: s.db, conn: s.db, leagueID: leagueID}
+}
+
+func (s *sqliteStorage) Close() error { // Close closes the database handle
+	return s.db.Close()
+}
+
+type sqliteStore struct { // sqliteStore is the LeagueStore of one league kept in the league.db SQLite database
+	db       sqlExecutor // Database or transaction the queries run on
+	conn     *sql.DB     // Database handle transactions are started on (nil inside a transaction)
+	leagueID int         // League every query is limited to
+}
+
+const teamColumns = "id, name, COALESCE(short_code, ''), points, played, won, drawn, lost, gf, ga, gd, strength, COALESCE(attack, strength), COALESCE(defense, strength), COALESCE(home_advantage, 0), COALESCE(elo, 0)" // Columns scanned into a Team
+
+func (s *sqliteStore) Teams() ([]Team, error) { // Teams returns every team with its current stats, including teams that have only had a bye
+	rows, err := s.db.Query("SELECT "+teamColumns+" FROM teams WHERE league_id = ? AND removed = 0 ORDER BY id", s.leagueID) // Query to retrieve team stats
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // Ensure rows are closed after processing
+
+	teams := []Team{} // Empty rather than nil so a league without teams encodes as []
+	for rows.Next() { // Iterate through each row of the query result
+		var team Team
+		if err := rows.Scan(&team.ID, &team.Name, &team.ShortCode, &team.Points, &team.Played, &team.Won, &team.Drawn, &team.Lost, &team.GF, &team.GA, &team.GD, &team.Strength, &team.Attack, &team.Defense, &team.HomeAdvantage, &team.Elo); err != nil {
+			return nil, err
+		}
+		teams = append(teams, team) // Add the team to the slice
+	}
+
+	return teams, rows.Err()
+}
+
+func (s *sqliteStore) Team(id int) (Team, error) { // Team returns one team with its current stats
+	var team Team
+	err := s.db.QueryRow("SELECT "+teamColumns+" FROM teams WHERE id = ? AND league_id = ? AND removed = 0", id, s.leagueID).
+		Scan(&team.ID, &team.Name, &team.ShortCode, &team.Points, &team.Played, &team.Won, &team.Drawn, &team.Lost, &team.GF, &team.GA, &team.GD, &team.Strength, &team.Attack, &team.Defense, &team.HomeAdvantage, &team.Elo) // Retrieve current team stats
+	if errors.Is(err, sql.ErrNoRows) {
+		return Team{}, errTeamNotFound
+	}
+	return team, err
+}
+
+func (s *sqliteStore) AddTeam(team Team) (Team, error) { // AddTeam inserts a team with empty stats
+	added := Team{Name: team.Name, ShortCode: team.ShortCode, Strength: team.Strength, Attack: team.Attack, Defense: team.Defense, HomeAdvantage: team.HomeAdvantage, Elo: eloForStrength(team.Strength)}
+	result, err := s.db.Exec("INSERT INTO teams (name, short_code, points, played, won, drawn, lost, gf, ga, gd, strength, attack, defense, home_advantage, elo, league_id) VALUES (?, ?, 0, 0, 0, 0, 0, 0, 0, 0, ?, ?, ?, ?, ?, ?)",
+		added.Name, added.ShortCode, added.Strength, added.Attack, added.Defense, added.HomeAdvantage, added.Elo, s.leagueID)
+	if err != nil {
+		return Team{}, err
+	}
+	teamID, err := result.LastInsertId()
+	if err != nil {
+		return Team{}, err
+	}
+	added.ID = int(teamID)
+	return added, nil
+}
+
+func (s *sqliteStore) EditTeam(team Team) error { // EditTeam saves a team's name, short code, strength, attack, defense, home advantage and rating
+	_, err := s.db.Exec("UPDATE teams SET name = ?, short_code = ?, strength = ?, attack = ?, defense = ?, home_advantage = ?, elo = ? WHERE id = ? AND league_id = ? AND removed = 0",
+		team.Name, team.ShortCode, team.Strength, team.Attack, team.Defense, team.HomeAdvantage, team.Elo, team.ID, s.leagueID)
+	return err
+}
+
+func (s *sqliteStore) RemoveTeam(id int) error { // RemoveTeam marks a team as removed; the row is kept so earlier seasons' matches still show its name
+	_, err := s.db.Exec("UPDATE teams SET removed = 1 WHERE id = ? AND league_id = ?", id, s.leagueID)
+	return err
+}
+
+func (s *sqliteStore) UpdateTeam(team Team) error { // UpdateTeam saves a team's stats, strength and rating
+	_, err := s.db.Exec("UPDATE teams SET points = ?, played = ?, won = ?, drawn = ?, lost = ?, gf = ?, ga = ?, gd = ?, strength = ?, elo = ? WHERE id = ? AND league_id = ?",
+		team.Points, team.Played, team.Won, team.Drawn, team.Lost, team.GF, team.GA, team.GD, team.Strength, team.Elo, team.ID, s.leagueID) // Update the team stats in the database
+	return err
+}
+
+func (s *sqliteStore) ResetTeamStats() error { // ResetTeamStats clears every team's stats for a new season, keeping names, strengths and ratings
+	_, err := s.db.Exec("UPDATE teams SET points = 0, played = 0, won = 0, drawn = 0, lost = 0, gf = 0, ga = 0, gd = 0 WHERE league_id = ?", s.leagueID)
+	return err
+}
+
+const matchColumns = `SELECT m.id, m.home_team_id, m.away_team_id, h.name, a.name, m.home_score, m.away_score, m.week, m.played
+        FROM matches m
+        JOIN teams h ON h.id = m.home_team_id
+        JOIN teams a ON a.id = m.away_team_id
+        JOIN seasons s ON s.id = m.season_id` // Matches joined with their team names and season, which gives their league
+
+func (s *sqliteStore) Matches(seasonID, week int) ([]Match, error) { // Matches returns a season's matches of a week (or of every week if week is 0) with team names
+	query := matchColumns + " WHERE m.season_id = ? AND s.league_id = ?"
+	args := []any{seasonID, s.leagueID}
+	if week > 0 {
+		query += " AND m.week = ?" // Only the requested week
+		args = append(args, week)
+	}
+	query += " ORDER BY m.week, m.id"
+
+	rows, err := s.db.Query(query, args...) // Query to retrieve matches joined with their team names
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // Ensure rows are closed after processing
+
+	matches := []Match{} // Empty rather than nil so a week without matches encodes as []
+	for rows.Next() {    // Iterate through each row of the query result
+		var match Match
+		if err := rows.Scan(&match.ID, &match.HomeTeamID, &match.AwayTeamID, &match.HomeTeam, &match.AwayTeam, &match.HomeScore, &match.AwayScore, &match.Week, &match.Played); err != nil {
+			return nil, err
+		}
+		matches = append(matches, match) // Add the match to the slice
+	}
+
+	return matches, rows.Err()
+}
+
+func (s *sqliteStore) Match(seasonID, matchID int) (Match, error) { // Match returns one match of a season with team names
+	var match Match
+	err := s.db.QueryRow(matchColumns+" WHERE m.id = ? AND m.season_id = ? AND s.league_id = ?", matchID, seasonID, s.leagueID).
+		Scan(&match.ID, &match.HomeTeamID, &match.AwayTeamID, &match.HomeTeam, &match.AwayTeam, &match.HomeScore, &match.AwayScore, &match.Week, &match.Played)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Match{}, errMatchNotFound // Unknown match, or a match of another season or league
+	}
+	return match, err
+}
+
+func (s *sqliteStore) AddFixtures(seasonID int, fixtures []Fixture) error { // AddFixtures inserts each fixture as an unplayed match
+	for _, fixture := range fixtures {
+		_, err := s.db.Exec("INSERT INTO matches (home_team_id, away_team_id, home_score, away_score, week, played, season_id) VALUES (?, ?, 0, 0, ?, 0, ?)",
+			fixture.HomeTeamID, fixture.AwayTeamID, fixture.Week, seasonID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) RemoveFixtures(seasonID int) error { // RemoveFixtures deletes a season's unplayed matches
+	_, err := s.db.Exec("DELETE FROM matches WHERE season_id = ? AND played = 0 AND season_id IN (SELECT id FROM seasons WHERE league_id = ?)", seasonID, s.leagueID)
+	return err
+}
+
+func (s *sqliteStore) SaveMatch(match Match) error { // SaveMatch saves a match result to its scheduled fixture
+	_, err := s.db.Exec("UPDATE matches SET home_score = ?, away_score = ?, played = 1 WHERE id = ? AND season_id IN (SELECT id FROM seasons WHERE league_id = ?)",
+		match.HomeScore, match.AwayScore, match.ID, s.leagueID) // Record the result and mark the fixture as played
+	return err
+}
+
+const seasonColumns = "id, current_week, weeks, archived, scheduled, fixture_seed" // Columns scanned into a Season
+
+func (s *sqliteStore) Season() (Season, error) { // Season returns the state of the current season
+	var season Season
+	err := s.db.QueryRow("SELECT "+seasonColumns+" FROM seasons WHERE league_id = ? ORDER BY id DESC LIMIT 1", s.leagueID).
+		Scan(&season.ID, &season.CurrentWeek, &season.Weeks, &season.Archived, &season.Scheduled, &season.FixtureSeed) // Query to retrieve the latest season
+	if errors.Is(err, sql.ErrNoRows) {
+		return Season{}, errNoSeason
+	}
+	if err != nil {
+		return Season{}, err
+	}
+	season.Finished = season.CurrentWeek >= season.Weeks
+	return season, nil
+}
+
+func (s *sqliteStore) Seasons() ([]Season, error) { // Seasons returns every season, newest first
+	rows, err := s.db.Query("SELECT "+seasonColumns+" FROM seasons WHERE league_id = ? ORDER BY id DESC", s.leagueID) // Query to retrieve all seasons
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // Ensure rows are closed by end of function
+
+	seasons := []Season{}
+	for rows.Next() {
+		var season Season
+		if err := rows.Scan(&season.ID, &season.CurrentWeek, &season.Weeks, &season.Archived, &season.Scheduled, &season.FixtureSeed); err != nil {
+			return nil, err
+		}
+		season.Finished = season.CurrentWeek >= season.Weeks
+		seasons = append(seasons, season) // Add season to list
+	}
+
+	return seasons, rows.Err()
+}
+
+func (s *sqliteStore) AddSeason(weeks int, fixtureSeed int64) (Season, error) { // AddSeason starts the season state at week 0, before its fixtures are generated
+	result, err := s.db.Exec("INSERT INTO seasons (current_week, weeks, league_id, scheduled, fixture_seed) VALUES (0, ?, ?, 0, ?)", weeks, s.leagueID, fixtureSeed)
+	if err != nil {
+		return Season{}, err
+	}
+	seasonID, err := result.LastInsertId()
+	if err != nil {
+		return Season{}, err
+	}
+	return Season{ID: int(seasonID), Weeks: weeks, Finished: weeks == 0, FixtureSeed: fixtureSeed}, nil
+}
+
+func (s *sqliteStore) SetSchedule(seasonID, weeks int, scheduled bool) error { // SetSchedule sets a season's length and whether its fixtures have been generated
+	_, err := s.db.Exec("UPDATE seasons SET weeks = ?, scheduled = ? WHERE id = ? AND league_id = ?", weeks, scheduled, seasonID, s.leagueID)
+	return err
+}
+
+func (s *sqliteStore) ClaimWeek(season Season, week int) error { // ClaimWeek advances the season to the given week, failing if the previous week is no longer the current one
+	result, err := s.db.Exec("UPDATE seasons SET current_week = ? WHERE id = ? AND league_id = ? AND current_week = ?", week, season.ID, s.leagueID, week-1) // Only advance from the week before
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return errWeekAlreadyPlayed // Another request has already played this week
+	}
+	return nil
+}
+
+func (s *sqliteStore) ArchiveSeason(seasonID int) error { // ArchiveSeason stores a copy of the league table as the season's final standings
+	result, err := s.db.Exec("UPDATE seasons SET archived = 1 WHERE id = ? AND league_id = ? AND archived = 0", seasonID, s.leagueID) // Archive each season only once
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil || updated == 0 {
+		return err // Already archived, or a season of another league
+	}
+
+	// Copy the current table into the archive
+	_, err = s.db.Exec(`INSERT INTO season_standings (season_id, team_id, name, short_code, points, played, won, drawn, lost, gf, ga, gd, strength, attack, defense, home_advantage, elo)
+        SELECT ?, id, name, short_code, points, played, won, drawn, lost, gf, ga, gd, strength, attack, defense, home_advantage, elo FROM teams WHERE league_id = ? AND removed = 0`, seasonID, s.leagueID)
+	return err
+}
+
+func (s *sqliteStore) ArchivedStandings(seasonID int) ([]Team, error) { // ArchivedStandings returns the final standings stored for an archived season
+	rows, err := s.db.Query("SELECT team_id, name, COALESCE(short_code, ''), points, played, won, drawn, lost, gf, ga, gd, strength, COALESCE(attack, strength), COALESCE(defense, strength), COALESCE(home_advantage, 0), COALESCE(elo, 0) FROM season_standings WHERE season_id = ? AND season_id IN (SELECT id FROM seasons WHERE league_id = ?) ORDER BY team_id", seasonID, s.leagueID) // Query to retrieve the archived table
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // Ensure rows are closed by end of function
+
+	teams := []Team{} // Empty rather than nil so a season without standings encodes as []
+	for rows.Next() {
+		var team Team
+		if err := rows.Scan(&team.ID, &team.Name, &team.ShortCode, &team.Points, &team.Played, &team.Won, &team.Drawn, &team.Lost, &team.GF, &team.GA, &team.GD, &team.Strength, &team.Attack, &team.Defense, &team.HomeAdvantage, &team.Elo); err != nil {
+			return nil, err
+		}
+		teams = append(teams, team) // Add team to list
+	}
+
+	return teams, rows.Err()
+}
+
+func (s *sqliteStore) Rules() (Rules, error) { // Rules returns the competition rules stored with the league
+	var tiebreakers string
+	var p PointsRules
+	var eloStrength bool
+	err := s.db.QueryRow("SELECT COALESCE(tiebreakers, ''), points_win, points_draw, points_loss, goals_bonus_at, goals_bonus, narrow_loss_bonus, elo_strength FROM leagues WHERE id = ?", s.leagueID).
+		Scan(&tiebreakers, &p.Win, &p.Draw, &p.Loss, &p.GoalsBonusAt, &p.GoalsBonus, &p.NarrowLossBonus, &eloStrength) // Query to retrieve the league's rules
+	if errors.Is(err, sql.ErrNoRows) {
+		return Rules{}, errLeagueNotFound
+	}
+	if err != nil {
+		return Rules{}, err
+	}
+
+	rules := Rules{Tiebreakers: []string{}, Points: p, EloStrength: eloStrength} // Empty rather than nil so a league ranked by points alone encodes as []
+	if tiebreakers != "" {
+		rules.Tiebreakers = strings.Split(tiebreakers, ",")
+	}
+	return rules, nil
+}
+
+func (s *sqliteStore) SetRules(rules Rules) error { // SetRules saves the league's competition rules
+	p := rules.Points
+	_, err := s.db.Exec("UPDATE leagues SET tiebreakers = ?, points_win = ?, points_draw = ?, points_loss = ?, goals_bonus_at = ?, goals_bonus = ?, narrow_loss_bonus = ?, elo_strength = ? WHERE id = ?",
+		strings.Join(rules.Tiebreakers, ","), p.Win, p.Draw, p.Loss, p.GoalsBonusAt, p.GoalsBonus, p.NarrowLossBonus, rules.EloStrength, s.leagueID)
+	return err
+}
+
+func (s *sqliteStore) Deductions(seasonID int) ([]Deduction, error) { // Deductions returns a season's point deductions with team names
+	rows, err := s.db.Query(`SELECT d.id, d.team_id, t.name, d.points, COALESCE(d.reason, '')
+        FROM point_deductions d
+        JOIN teams t ON t.id = d.team_id
+        WHERE d.season_id = ? AND d.season_id IN (SELECT id FROM seasons WHERE league_id = ?) ORDER BY d.id`, seasonID, s.leagueID) // Query to retrieve the season's deductions
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // Ensure rows are closed by end of function
+
+	deductions := []Deduction{} // Empty rather than nil so a season without deductions encodes as []
+	for rows.Next() {
+		var deduction Deduction
+		if err := rows.Scan(&deduction.ID, &deduction.TeamID, &deduction.Team, &deduction.Points, &deduction.Reason); err != nil {
+			return nil, err
+		}
+		deductions = append(deductions, deduction)
+	}
+
+	return deductions, rows.Err()
+}
+
+func (s *sqliteStore) AddDeduction(seasonID int, deduction Deduction) (Deduction, error) { // AddDeduction inserts a point deduction
+	result, err := s.db.Exec("INSERT INTO point_deductions (season_id, team_id, points, reason) VALUES (?, ?, ?, ?)", seasonID, deduction.TeamID, deduction.Points, deduction.Reason)
+	if err != nil {
+		return Deduction{}, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return Deduction{}, err
+	}
+	deduction.ID = int(id)
+	return deduction, nil
+}
+
+func (s *sqliteStore) RemoveDeduction(id int) error { // RemoveDeduction deletes a point deduction
+	_, err := s.db.Exec("DELETE FROM point_deductions WHERE id = ? AND season_id IN (SELECT id FROM seasons WHERE league_id = ?)", id, s.leagueID)
+	return err
+}
+
+func (s *sqliteStore) Ratings(seasonID int) ([]Rating, error) { // Ratings returns a season's rating history with team names
+	rows, err := s.db.Query(`SELECT r.team_id, t.name, r.week, r.rating
+        FROM elo_ratings r
+        JOIN teams t ON t.id = r.team_id
+        WHERE r.season_id = ? AND r.season_id IN (SELECT id FROM seasons WHERE league_id = ?) ORDER BY r.week, r.team_id`, seasonID, s.leagueID) // Query to retrieve the season's ratings
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // Ensure rows are closed by end of function
+
+	ratings := []Rating{} // Empty rather than nil so a season without ratings encodes as []
+	for rows.Next() {
+		var rating Rating
+		if err := rows.Scan(&rating.TeamID, &rating.Team, &rating.Week, &rating.Rating); err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, rating)
+	}
+
+	return ratings, rows.Err()
+}
+
+func (s *sqliteStore) Events(seasonID, week int) ([]MatchEvent, error) { // Events returns the timeline events of a season's matches of a week (or of every week if week is 0) with team names
+	query := `SELECT e.match_id, e.minute, e.type, e.team_id, t.name
+        FROM match_events e
+        JOIN matches m ON m.id = e.match_id
+        JOIN seasons s ON s.id = m.season_id
+        JOIN teams t ON t.id = e.team_id
+        WHERE m.season_id = ? AND s.league_id = ?`
+	args := []any{seasonID, s.leagueID}
+	if week > 0 {
+		query += " AND m.week = ?" // Only the requested week
+		args = append(args, week)
+	}
+	query += " ORDER BY m.week, m.id, e.minute, e.id"
+
+	rows, err := s.db.Query(query, args...) // Query to retrieve the events joined with their team names
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // Ensure rows are closed by end of function
+
+	events := []MatchEvent{} // Empty rather than nil so a week without events encodes as []
+	for rows.Next() {
+		var event MatchEvent
+		if err := rows.Scan(&event.MatchID, &event.Minute, &event.Type, &event.TeamID, &event.Team); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (s *sqliteStore) SaveEvents(matchID int, events []MatchEvent) error { // SaveEvents replaces the timeline of a match
+	if _, err := s.db.Exec("DELETE FROM match_events WHERE match_id = ? AND match_id IN (SELECT m.id FROM matches m JOIN seasons s ON s.id = m.season_id WHERE s.league_id = ?)", matchID, s.leagueID); err != nil {
+		return err
+	}
+	for _, event := range events {
+		if _, err := s.db.Exec("INSERT INTO match_events (match_id, minute, type, team_id) VALUES (?, ?, ?, ?)", matchID, event.Minute, event.Type, event.TeamID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) SaveRatings(seasonID, week int, ratings []Rating) error { // SaveRatings replaces the ratings stored for a week of a season
+	if _, err := s.db.Exec("DELETE FROM elo_ratings WHERE season_id = ? AND week = ? AND season_id IN (SELECT id FROM seasons WHERE league_id = ?)", seasonID, week, s.leagueID); err != nil {
+		return err
+	}
+	for _, rating := range ratings {
+		if _, err := s.db.Exec("INSERT INTO elo_ratings (season_id, week, team_id, rating) VALUES (?, ?, ?, ?)", seasonID, week, rating.TeamID, rating.Rating); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) Update(fn func(LeagueStore) error) error { // Update runs fn in a transaction, rolling back if it fails
+	if s.conn == nil {
+		return fn(s) // Already inside a transaction
+	}
+
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // Roll back unless the transaction is committed
+
+	if err := fn(&sqliteStore{db: tx, leagueID: s.leagueID}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
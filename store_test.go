@@ -0,0 +1,237 @@
+package main
+
+import ( // Import required packages:
+	"errors"        // For matching error values
+	"path/filepath" // For the temporary database path
+	"testing"       // For the test framework
+)
+
+func testStorages(t *testing.T) map[string]func() Storage { // testStorages returns a constructor for an empty storage of each backend; the SQLite one uses a temporary file
+	return map[string]func() Storage{
+		"memory": func() Storage { return newMemoryStorage() },
+		"sqlite": func() Storage {
+			storage, err := openStore("sqlite", filepath.Join(t.TempDir(), "league.db")) // Opened as the server opens it, but never the application's league.db
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { storage.Close() })
+			return storage
+		},
+	}
+}
+
+func TestLeagueStore(t *testing.T) { // Every backend stores teams, fixtures, results and seasons the same way
+	for name, open := range testStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			store := open().League(defaultLeagueID)
+			if _, err := store.Season(); !errors.Is(err, errNoSeason) {
+				t.Errorf("Season() of an empty league: error = %v, want %v", err, errNoSeason)
+			}
+
+			for _, team := range []Team{{Name: "Chelsea", ShortCode: "CHE", Strength: 3}, {Name: "Arsenal", ShortCode: "ARS", Strength: 3}} {
+				if _, err := store.AddTeam(team); err != nil {
+					t.Fatal(err)
+				}
+			}
+			teams, err := store.Teams()
+			if err != nil || len(teams) != 2 || teams[0].Name != "Chelsea" || teams[1].Name != "Arsenal" {
+				t.Fatalf("Teams() = %+v, %v, want Chelsea and Arsenal", teams, err)
+			}
+			if _, err := store.Team(999); !errors.Is(err, errTeamNotFound) {
+				t.Errorf("Team(999): error = %v, want %v", err, errTeamNotFound)
+			}
+
+			season, err := store.AddSeason(2, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			home, away := teams[0].ID, teams[1].ID
+			if err := store.AddFixtures(season.ID, []Fixture{{HomeTeamID: home, AwayTeamID: away, Week: 1}, {HomeTeamID: away, AwayTeamID: home, Week: 2}}); err != nil {
+				t.Fatal(err)
+			}
+			week, err := store.Matches(season.ID, 1)
+			if err != nil || len(week) != 1 || week[0].HomeTeam != "Chelsea" || week[0].AwayTeam != "Arsenal" || week[0].Played {
+				t.Fatalf("Matches(week 1) = %+v, %v, want Chelsea v Arsenal unplayed", week, err)
+			}
+
+			match := week[0]
+			match.HomeScore, match.AwayScore, match.Played = 2, 1, true
+			if err := store.SaveMatch(match); err != nil {
+				t.Fatal(err)
+			}
+			if saved, err := store.Match(season.ID, match.ID); err != nil || saved != match {
+				t.Errorf("Match() = %+v, %v, want %+v", saved, err, match)
+			}
+			if _, err := store.Match(season.ID+1, match.ID); !errors.Is(err, errMatchNotFound) {
+				t.Errorf("Match() of another season: error = %v, want %v", err, errMatchNotFound)
+			}
+
+			if err := store.ClaimWeek(season, 2); !errors.Is(err, errWeekAlreadyPlayed) {
+				t.Errorf("ClaimWeek(2) before week 1: error = %v, want %v", err, errWeekAlreadyPlayed)
+			}
+			if err := store.ClaimWeek(season, 1); err != nil {
+				t.Fatal(err)
+			}
+			if current, err := store.Season(); err != nil || current.ID != season.ID || current.CurrentWeek != 1 || current.Finished {
+				t.Errorf("Season() = %+v, %v, want week 1 of 2", current, err)
+			}
+
+			chelsea := teams[0]
+			chelsea.Points, chelsea.Played, chelsea.Won = 3, 1, 1
+			if err := store.UpdateTeam(chelsea); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.ArchiveSeason(season.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.ArchiveSeason(season.ID); err != nil { // Archiving twice keeps the first standings
+				t.Fatal(err)
+			}
+			if err := store.ResetTeamStats(); err != nil {
+				t.Fatal(err)
+			}
+			archived, err := store.ArchivedStandings(season.ID)
+			if err != nil || len(archived) != 2 || archived[0].Points != 3 {
+				t.Errorf("ArchivedStandings() = %+v, %v, want Chelsea on 3 points", archived, err)
+			}
+			if reset, err := store.Team(chelsea.ID); err != nil || reset.Points != 0 || reset.Strength != 3 {
+				t.Errorf("Team() after reset = %+v, %v, want no points and strength 3", reset, err)
+			}
+		})
+	}
+}
+
+func TestLeagueStoreUpdate(t *testing.T) { // Update keeps every change of a successful function and none of a failed one
+	failed := errors.New("failed")
+	for name, open := range testStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			store := open().League(defaultLeagueID)
+			err := store.Update(func(tx LeagueStore) error {
+				if _, err := tx.AddTeam(Team{Name: "Chelsea", ShortCode: "CHE", Strength: 2}); err != nil {
+					return err
+				}
+				_, err := tx.AddTeam(Team{Name: "Arsenal", ShortCode: "ARS", Strength: 2})
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = store.Update(func(tx LeagueStore) error {
+				teams, err := tx.Teams()
+				if err != nil {
+					return err
+				}
+				teams[0].Strength = 4
+				if err := tx.EditTeam(teams[0]); err != nil {
+					return err
+				}
+				if _, err := tx.AddSeason(2, 0); err != nil {
+					return err
+				}
+				return failed
+			})
+			if !errors.Is(err, failed) {
+				t.Fatalf("Update() = %v, want %v", err, failed)
+			}
+
+			teams, err := store.Teams()
+			if err != nil || len(teams) != 2 || teams[0].Strength != 2 {
+				t.Errorf("Teams() = %+v, %v, want both teams with strength 2", teams, err)
+			}
+			if _, err := store.Season(); !errors.Is(err, errNoSeason) {
+				t.Errorf("Season() after a failed Update: error = %v, want %v", err, errNoSeason)
+			}
+		})
+	}
+}
+
+func TestStorageLeagues(t *testing.T) { // Leagues are found by code and never see each other's teams or seasons
+	for name, open := range testStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := open()
+			league, err := storage.CreateLeague("abcdefgh", "Cup", func(tx LeagueStore) error {
+				return SeedDatabase(tx, []string{"Everton", "Fulham", "Brentford"}, 1)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found, err := storage.FindLeague("abcdefgh"); err != nil || found != league {
+				t.Errorf("FindLeague() = %+v, %v, want %+v", found, err, league)
+			}
+			if _, err := storage.FindLeague("unknown"); !errors.Is(err, errLeagueNotFound) {
+				t.Errorf("FindLeague(unknown): error = %v, want %v", err, errLeagueNotFound)
+			}
+
+			if err := SeedDatabase(storage.League(defaultLeagueID), []string{"Chelsea", "Arsenal"}, 1); err != nil {
+				t.Fatal(err)
+			}
+			for _, tt := range []struct {
+				leagueID, teams, weeks int
+			}{
+				{league.ID, 3, seasonLength(3)},
+				{defaultLeagueID, 2, seasonLength(2)},
+			} {
+				store := storage.League(tt.leagueID)
+				teams, err := store.Teams()
+				if err != nil || len(teams) != tt.teams {
+					t.Errorf("league %d has %d teams, %v, want %d", tt.leagueID, len(teams), err, tt.teams)
+				}
+				seasons, err := store.Seasons()
+				if err != nil || len(seasons) != 1 || seasons[0].Weeks != tt.weeks {
+					t.Errorf("league %d seasons = %+v, %v, want one of %d weeks", tt.leagueID, seasons, err, tt.weeks)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateLeagueRollback(t *testing.T) { // A league whose seeding fails is not stored, so its code can be used again
+	for name, open := range testStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			storage := open()
+			failed := errors.New("seed failed")
+			_, err := storage.CreateLeague("abcdefgh", "Cup", func(tx LeagueStore) error {
+				if _, err := tx.AddTeam(Team{Name: "Everton", ShortCode: "EVE", Strength: 2}); err != nil {
+					return err
+				}
+				return failed
+			})
+			if !errors.Is(err, failed) {
+				t.Fatalf("CreateLeague() error = %v, want %v", err, failed)
+			}
+			if _, err := storage.FindLeague("abcdefgh"); !errors.Is(err, errLeagueNotFound) {
+				t.Errorf("FindLeague() after a failed seed: error = %v, want %v", err, errLeagueNotFound)
+			}
+
+			league, err := storage.CreateLeague("abcdefgh", "Cup", func(LeagueStore) error { return nil })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if teams, err := storage.League(league.ID).Teams(); err != nil || len(teams) != 0 {
+				t.Errorf("Teams() of the league created again = %+v, %v, want none", teams, err)
+			}
+		})
+	}
+}
+
+func TestSeedDatabaseRollback(t *testing.T) { // A league whose first season cannot be started keeps none of the seeded teams
+	db, err := SetupDatabase(filepath.Join(t.TempDir(), "league.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := newSQLiteStorage(db)
+	defer storage.Close()
+
+	_, err = db.Exec("CREATE TRIGGER no_seasons BEFORE INSERT ON seasons BEGIN SELECT RAISE(ABORT, 'no seasons'); END") // Make StartSeason fail after the teams are added
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := storage.League(defaultLeagueID)
+	if err := SeedDatabase(store, []string{"Chelsea", "Arsenal"}, 1); err == nil {
+		t.Fatal("SeedDatabase() succeeded without a season")
+	}
+	if teams, err := store.Teams(); err != nil || len(teams) != 0 {
+		t.Errorf("Teams() after a failed seed = %+v, %v, want none", teams, err)
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+import ( // Import required packages:
+	"encoding/json" // For JSON decoding
+	"errors"        // For creating error values
+	"fmt"           // For formatted errors
+	"net/http"      // For HTTP request handling
+	"strconv"       // For converting strings to integers
+	"strings"       // For trimming and upper-casing names and codes
+)
+
+var ( // Errors returned when the teams of a league cannot be changed
+	errInvalidTeam   = errors.New("invalid team")                                                                             // Wrapped with the field that is missing or out of range
+	errDuplicateTeam = errors.New("duplicate team")                                                                           // Wrapped with the name or short code that is already used
+	errTeamsLocked   = errors.New("teams cannot be changed once the season's first week has been played; start a new season") // Returned after the first week of a season has been played
+	errTooFewTeams   = errors.New("a league needs at least 2 teams")                                                          // Returned when removing one of the last 2 teams
+)
+
+const (
+	minStrength      = 1  // Weakest team strength, attack or defense
+	maxStrength      = 4  // Strongest team strength, attack or defense
+	maxHomeAdvantage = 50 // Largest home advantage, in percent
+	maxTeamNameLen   = 30 // Longest team name
+	minShortCodeLen  = 2  // Shortest team short code
+	maxShortCodeLen  = 4  // Longest team short code
+)
+
+type teamInput struct { // teamInput is the JSON body of POST and PUT /api/teams; fields left out of a PUT keep their value
+	Name      *string `json:"name"`      // Team name
+	ShortCode *string `json:"shortCode"` // Short code, derived from the name when left out of a POST
+	strengthsInput
+}
+
+type strengthsInput struct { // strengthsInput holds the match engine parameters of a team in a request; fields left out keep their value
+	Strength      *int `json:"strength"`      // Team strength (1-4)
+	Attack        *int `json:"attack"`        // Attacking strength (1-4), the strength when left out
+	Defense       *int `json:"defense"`       // Defensive strength (1-4), the strength when left out
+	HomeAdvantage *int `json:"homeAdvantage"` // Extra goals expected at home, in percent (0-50)
+}
+
+func (input strengthsInput) apply(team Team) Team { // apply sets the parameters given on a team; attack, defense and the rating follow a new strength unless attack and defense are given too
+	if input.Strength != nil && *input.Strength != team.Strength {
+		team.Strength = *input.Strength
+		team.Attack, team.Defense = team.Strength, team.Strength
+		team.Elo = eloForStrength(team.Strength) // A new strength restarts the team's rating; /changeStrengths keeps the rating once the season has started
+	}
+	if input.Attack != nil {
+		team.Attack = *input.Attack
+	}
+	if input.Defense != nil {
+		team.Defense = *input.Defense
+	}
+	if input.HomeAdvantage != nil {
+		team.HomeAdvantage = *input.HomeAdvantage
+	}
+	return team
+}
+
+func (srv *server) teamsAPIHandler(w http.ResponseWriter, r *http.Request) { // teamsAPIHandler lists (GET), adds (POST), edits (PUT ?id=N) and removes (DELETE ?id=N) the teams of a league
+	league, store, ok := srv.requestLeague(w, r) // League selected by the league parameter
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		teams, err := store.Teams()
+		if err != nil {
+			storeError(w, r, err, "Failed to fetch teams") // Return error if query fails
+			return
+		}
+		writeJSON(w, teams)
+		return
+	}
+	if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		writeError(w, r, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed") // Only GET, POST, PUT and DELETE are supported
+		return
+	}
+
+	var input teamInput
+	if r.Method != http.MethodDelete {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			writeError(w, r, http.StatusBadRequest, "invalid_input", "Invalid input") // Return error for invalid body
+			return
+		}
+	}
+
+	teamID := 0
+	if r.Method != http.MethodPost {
+		var err error
+		teamID, err = strconv.Atoi(r.URL.Query().Get("id")) // Convert id from string to int
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "invalid_input", "Invalid id parameter") // Return error for missing or invalid id
+			return
+		}
+	}
+
+	unlock, ok := srv.lockLeague(w, r, league.ID) // Only one request may change the league at a time
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	switch r.Method {
+	case http.MethodPost:
+		team, err := addTeam(store, input)
+		if err != nil {
+			storeError(w, r, err, "Failed to add team") // 400 for invalid fields, 409 for duplicates or after the first week
+			return
+		}
+		w.Header().Set("Content-Type", "application/json") // Set before the status, which sends the headers
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, team)
+	case http.MethodPut:
+		team, err := editTeam(store, teamID, input)
+		if err != nil {
+			storeError(w, r, err, "Failed to edit team") // 404 for unknown teams, 400 for invalid fields, 409 for duplicates or after the first week
+			return
+		}
+		writeJSON(w, team)
+	case http.MethodDelete:
+		if err := removeTeam(store, teamID); err != nil {
+			storeError(w, r, err, "Failed to remove team") // 404 for unknown teams, 409 for the last 2 teams or after the first week
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func addTeam(store LeagueStore, input teamInput) (Team, error) { // addTeam validates and adds a team to a league whose season has not started yet
+	if input.Name == nil || input.Strength == nil {
+		return Team{}, fmt.Errorf("%w: name and strength are required", errInvalidTeam)
+	}
+
+	var team Team
+	err := store.Update(func(tx LeagueStore) error { // Check and add in one transaction
+		teams, err := editableTeams(tx)
+		if err != nil {
+			return err
+		}
+
+		team = input.apply(Team{Name: *input.Name})
+		if input.ShortCode != nil {
+			team.ShortCode = *input.ShortCode
+		}
+		if team, err = checkTeam(team, teams); err != nil {
+			return err
+		}
+
+		if team, err = tx.AddTeam(team); err != nil {
+			return err
+		}
+		return rescheduleSeason(tx) // The season grows with the league
+	})
+	return team, err
+}
+
+func editTeam(store LeagueStore, teamID int, input teamInput) (Team, error) { // editTeam renames a team or changes its short code, strength, attack, defense or home advantage before the season starts
+	var team Team
+	err := store.Update(func(tx LeagueStore) error { // Check and save in one transaction
+		teams, err := editableTeams(tx)
+		if err != nil {
+			return err
+		}
+
+		if team, err = tx.Team(teamID); err != nil {
+			return err // errTeamNotFound for unknown or removed teams
+		}
+		if input.Name != nil {
+			team.Name = *input.Name
+		}
+		if input.ShortCode != nil {
+			team.ShortCode = *input.ShortCode
+		}
+		team = input.apply(team) // A new strength also resets the rating
+		if team, err = checkTeam(team, teams); err != nil {
+			return err
+		}
+
+		if err := tx.EditTeam(team); err != nil {
+			return err
+		}
+		return rescheduleSeason(tx) // Records the new starting rating
+	})
+	return team, err
+}
+
+func removeTeam(store LeagueStore, teamID int) error { // removeTeam removes a team from a league before the season starts
+	return store.Update(func(tx LeagueStore) error { // Check and remove in one transaction
+		teams, err := editableTeams(tx)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Team(teamID); err != nil {
+			return err // errTeamNotFound for unknown or removed teams
+		}
+		if len(teams) <= 2 {
+			return errTooFewTeams
+		}
+
+		if err := tx.RemoveTeam(teamID); err != nil {
+			return err
+		}
+		return rescheduleSeason(tx) // The season shrinks with the league
+	})
+}
+
+func editableTeams(store LeagueStore) ([]Team, error) { // editableTeams returns the league's teams, or errTeamsLocked once the current season's first week has been played
+	season, err := store.Season()
+	if err == nil && season.CurrentWeek > 0 {
+		return nil, errTeamsLocked
+	}
+	if err != nil && !errors.Is(err, errNoSeason) {
+		return nil, err
+	}
+	return store.Teams()
+}
+
+func rescheduleSeason(store LeagueStore) error { // rescheduleSeason generates the fixtures of the current season again after its teams changed before its first week
+	season, err := store.Season()
+	if errors.Is(err, errNoSeason) {
+		return nil // The first season is scheduled when it starts
+	}
+	if err != nil {
+		return err
+	}
+	_, err = scheduleSeason(store, season)
+	return err
+}
+
+func checkTeam(team Team, teams []Team) (Team, error) { // checkTeam normalizes a team's name and short code and rejects invalid values and names or codes used by another team
+	team.Name = strings.TrimSpace(team.Name)
+	if team.Name == "" || len(team.Name) > maxTeamNameLen {
+		return Team{}, fmt.Errorf("%w: name must be 1 to %d characters", errInvalidTeam, maxTeamNameLen)
+	}
+	if err := checkStrengths(team); err != nil {
+		return Team{}, err
+	}
+
+	var others []Team // Teams the name and short code must differ from
+	for _, other := range teams {
+		if other.ID != team.ID {
+			others = append(others, other)
+		}
+	}
+
+	team.ShortCode = strings.ToUpper(strings.TrimSpace(team.ShortCode))
+	if team.ShortCode == "" {
+		team.ShortCode = defaultShortCode(team.Name, others)
+	}
+	if !validShortCode(team.ShortCode) {
+		return Team{}, fmt.Errorf("%w: short code must be %d to %d letters or digits", errInvalidTeam, minShortCodeLen, maxShortCodeLen)
+	}
+
+	for _, other := range others {
+		if strings.EqualFold(other.Name, team.Name) {
+			return Team{}, fmt.Errorf("%w: name %q is already used", errDuplicateTeam, team.Name)
+		}
+		if other.ShortCode == team.ShortCode {
+			return Team{}, fmt.Errorf("%w: short code %q is already used", errDuplicateTeam, team.ShortCode)
+		}
+	}
+	return team, nil
+}
+
+func checkStrengths(team Team) error { // checkStrengths rejects a strength, attack or defense out of range and a home advantage out of range
+	for _, field := range []struct {
+		name  string
+		value int
+	}{{"strength", team.Strength}, {"attack", team.Attack}, {"defense", team.Defense}} {
+		if field.value < minStrength || field.value > maxStrength {
+			return fmt.Errorf("%w: %s must be between %d and %d", errInvalidTeam, field.name, minStrength, maxStrength)
+		}
+	}
+	if team.HomeAdvantage < 0 || team.HomeAdvantage > maxHomeAdvantage {
+		return fmt.Errorf("%w: homeAdvantage must be between 0 and %d", errInvalidTeam, maxHomeAdvantage)
+	}
+	return nil
+}
+
+func validShortCode(code string) bool { // validShortCode reports whether a short code is 2 to 4 upper-case letters or digits
+	if len(code) < minShortCodeLen || len(code) > maxShortCodeLen {
+		return false
+	}
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func defaultShortCode(name string, teams []Team) string { // defaultShortCode derives a short code from a team name, e.g. CHE for Chelsea, numbering it (MA2) when another team already uses it
+	var letters []byte
+	for _, c := range strings.ToUpper(name) { // Keep the letters and digits of the name
+		if (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			letters = append(letters, byte(c))
+		}
+	}
+	for len(letters) < minShortCodeLen {
+		letters = append(letters, 'X') // Pad names that are too short, or have no usable characters
+	}
+	base := string(letters[:min(len(letters), 3)])
+
+	taken := make(map[string]bool) // Short codes of the other teams
+	for _, team := range teams {
+		taken[team.ShortCode] = true
+	}
+
+	code := base
+	for n := 2; taken[code]; n++ {
+		code = base[:2] + strconv.Itoa(n)
+	}
+	return code
+}
@@ -0,0 +1,148 @@
+package main
+
+import ( // Import required packages:
+	"errors"  // For matching error values
+	"testing" // For the test framework
+)
+
+func TestDefaultShortCode(t *testing.T) { // Short codes take the first three letters or digits of the name, numbered when already used
+	tests := []struct {
+		name  string
+		taken []string
+		want  string
+	}{
+		{"Chelsea", nil, "CHE"},
+		{"AFC Bournemouth", nil, "AFC"},
+		{"Manchester City", []string{"MAN"}, "MA2"},
+		{"Manchester United", []string{"MAN", "MA2"}, "MA3"},
+		{"Q", nil, "QX"},
+		{"!!", nil, "XX"},
+	}
+
+	for _, tt := range tests {
+		var teams []Team
+		for _, code := range tt.taken {
+			teams = append(teams, Team{ShortCode: code})
+		}
+		if got := defaultShortCode(tt.name, teams); got != tt.want {
+			t.Errorf("defaultShortCode(%q, %v) = %q, want %q", tt.name, tt.taken, got, tt.want)
+		}
+	}
+}
+
+func TestCheckTeam(t *testing.T) { // Names and short codes are trimmed and must be valid and unused by the other teams
+	teams := []Team{{ID: 1, Name: "Chelsea", ShortCode: "CHE", Strength: 2, Attack: 2, Defense: 2}, {ID: 2, Name: "Arsenal", ShortCode: "ARS", Strength: 3, Attack: 3, Defense: 3}}
+	tests := []struct {
+		name    string
+		team    Team
+		want    Team
+		wantErr error
+	}{
+		{"new team", Team{Name: "  Everton ", ShortCode: "eve", Strength: 1, Attack: 1, Defense: 1}, Team{Name: "Everton", ShortCode: "EVE", Strength: 1, Attack: 1, Defense: 1}, nil},
+		{"default short code", Team{Name: "Everton", Strength: 4, Attack: 4, Defense: 4}, Team{Name: "Everton", ShortCode: "EVE", Strength: 4, Attack: 4, Defense: 4}, nil},
+		{"unchanged team", teams[0], teams[0], nil},
+		{"empty name", Team{Name: " ", Strength: 1, Attack: 1, Defense: 1}, Team{}, errInvalidTeam},
+		{"strength too high", Team{Name: "Everton", Strength: 5, Attack: 4, Defense: 4}, Team{}, errInvalidTeam},
+		{"attack too low", Team{Name: "Everton", Strength: 2, Attack: 0, Defense: 2}, Team{}, errInvalidTeam},
+		{"home advantage too high", Team{Name: "Everton", Strength: 2, Attack: 2, Defense: 2, HomeAdvantage: 60}, Team{}, errInvalidTeam},
+		{"invalid short code", Team{Name: "Everton", ShortCode: "E-V", Strength: 1, Attack: 1, Defense: 1}, Team{}, errInvalidTeam},
+		{"name of another team", Team{ID: 1, Name: "arsenal", Strength: 1, Attack: 1, Defense: 1}, Team{}, errDuplicateTeam},
+		{"short code of another team", Team{Name: "Everton", ShortCode: "CHE", Strength: 1, Attack: 1, Defense: 1}, Team{}, errDuplicateTeam},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checkTeam(tt.team, teams)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("checkTeam() = %+v, %v, want %+v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTeamChanges(t *testing.T) { // Teams can be added, edited and removed, rescheduling the season, until its first week is played
+	store := newMemoryStorage().League(defaultLeagueID)
+	if err := SeedDatabase(store, []string{"Chelsea", "Arsenal"}, 1); err != nil {
+		t.Fatal(err)
+	}
+	name, strength := "Everton", 2
+
+	everton, err := addTeam(store, teamInput{Name: &name, strengthsInput: strengthsInput{Strength: &strength}})
+	if err != nil || everton.ShortCode != "EVE" {
+		t.Fatalf("addTeam() = %+v, %v", everton, err)
+	}
+	season := currentSeason(t, store)
+	if season.Weeks != seasonLength(3) {
+		t.Errorf("season has %d weeks after adding a third team, want %d", season.Weeks, seasonLength(3))
+	}
+	if matches, err := store.Matches(season.ID, 0); err != nil || len(matches) != 6 {
+		t.Errorf("season has %d fixtures after adding a third team, %v, want 6", len(matches), err)
+	}
+	if _, err := addTeam(store, teamInput{Name: &name, strengthsInput: strengthsInput{Strength: &strength}}); !errors.Is(err, errDuplicateTeam) {
+		t.Errorf("adding %s twice: error = %v, want %v", name, err, errDuplicateTeam)
+	}
+
+	renamed := "Everton FC"
+	if everton, err = editTeam(store, everton.ID, teamInput{Name: &renamed}); err != nil || everton.Name != renamed || everton.Strength != strength {
+		t.Errorf("editTeam() = %+v, %v, want the new name and the same strength", everton, err)
+	}
+	if _, err := editTeam(store, 999, teamInput{Name: &renamed}); !errors.Is(err, errTeamNotFound) {
+		t.Errorf("editing an unknown team: error = %v, want %v", err, errTeamNotFound)
+	}
+
+	if err := removeTeam(store, everton.ID); err != nil {
+		t.Fatal(err)
+	}
+	season = currentSeason(t, store)
+	if season.Weeks != seasonLength(2) {
+		t.Errorf("season has %d weeks after removing the third team, want %d", season.Weeks, seasonLength(2))
+	}
+	matches, err := store.Matches(season.ID, 0)
+	if err != nil || len(matches) != 2 {
+		t.Errorf("season has %d fixtures after removing the third team, %v, want 2", len(matches), err)
+	}
+	for _, match := range matches {
+		if match.HomeTeamID == everton.ID || match.AwayTeamID == everton.ID {
+			t.Errorf("removed team still has fixture %+v", match)
+		}
+	}
+	teams, err := store.Teams()
+	if err != nil || len(teams) != 2 {
+		t.Fatalf("Teams() = %+v, %v, want 2 teams", teams, err)
+	}
+	if err := removeTeam(store, teams[0].ID); !errors.Is(err, errTooFewTeams) {
+		t.Errorf("removing one of the last 2 teams: error = %v, want %v", err, errTooFewTeams)
+	}
+
+	if err := store.ClaimWeek(season, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := addTeam(store, teamInput{Name: &name, strengthsInput: strengthsInput{Strength: &strength}}); !errors.Is(err, errTeamsLocked) {
+		t.Errorf("adding a team after the first week: error = %v, want %v", err, errTeamsLocked)
+	}
+	if err := removeTeam(store, teams[0].ID); !errors.Is(err, errTeamsLocked) {
+		t.Errorf("removing a team after the first week: error = %v, want %v", err, errTeamsLocked)
+	}
+}
+
+func TestStrengthsApply(t *testing.T) { // Attack and defense follow a new strength unless they are given too, the rating restarts from a new strength, and parameters left out keep their value
+	value := func(n int) *int { return &n }
+	team := Team{Strength: 2, Attack: 3, Defense: 1, HomeAdvantage: 10, Elo: 1234}
+	tests := []struct {
+		name  string
+		input strengthsInput
+		want  Team
+	}{
+		{"nothing given", strengthsInput{}, team},
+		{"new strength", strengthsInput{Strength: value(4)}, Team{Strength: 4, Attack: 4, Defense: 4, HomeAdvantage: 10, Elo: eloForStrength(4)}},
+		{"new strength and attack", strengthsInput{Strength: value(4), Attack: value(2)}, Team{Strength: 4, Attack: 2, Defense: 4, HomeAdvantage: 10, Elo: eloForStrength(4)}},
+		{"same strength", strengthsInput{Strength: value(2)}, team},
+		{"defense and home advantage", strengthsInput{Defense: value(4), HomeAdvantage: value(0)}, Team{Strength: 2, Attack: 3, Defense: 4, Elo: 1234}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.input.apply(team); got != tt.want {
+			t.Errorf("%s: apply() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}